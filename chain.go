@@ -1,8 +1,10 @@
 package oops
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/calebcase/oops/lines"
@@ -12,8 +14,8 @@ import (
 type ChainError []error
 
 var (
-	_ error     = ChainError{}
-	_ unwrapper = ChainError{}
+	_ error          = ChainError{}
+	_ multiUnwrapper = ChainError{}
 )
 
 // Error implements the implied interface for error.
@@ -21,39 +23,25 @@ func (ce ChainError) Error() string {
 	return fmt.Sprintf("%v", ce)
 }
 
-// Unwrap implements the implied interface for errors.Unwrap.
-func (ce ChainError) Unwrap() error {
+// Unwrap implements the implied interface for errors.Unwrap. All links in the
+// chain are returned (errors.Is and errors.As match against every link). The
+// returned slice is a copy.
+func (ce ChainError) Unwrap() []error {
 	if len(ce) == 0 {
 		return nil
 	}
 
-	if len(ce) == 1 {
-		return ce[0]
-	}
-
-	return ChainError(ce[1:])
-}
-
-// Is implements the implied interface for errors.Is.
-func (ce ChainError) Is(err error) bool {
-	if len(ce) == 0 {
-		return false
-	}
-
-	return errors.Is(ce[0], err)
-}
-
-// As implements the implied interface for errors.As.
-func (ce ChainError) As(target any) bool {
-	if len(ce) == 0 {
-		return false
-	}
-
-	return errors.As(ce[0], target)
+	return append([]error(nil), ce...)
 }
 
 // Format implements fmt.Format.
 func (ce ChainError) Format(f fmt.State, verb rune) {
+	ce.formatRelative(f, verb, nil, redactPolicy.Load())
+}
+
+// formatRelative implements relativeFormatter. The first link is formatted
+// relative to ref and the others relative to the link before them.
+func (ce ChainError) formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy) {
 	if len(ce) == 0 {
 		fmt.Fprintf(f, "<nil>")
 
@@ -75,7 +63,12 @@ func (ce ChainError) Format(f fmt.State, verb rune) {
 
 	fmt.Fprintf(f, "chain(len=%d):\n", len(ce))
 	for i, err := range ce {
-		lines := lines.Indent(lines.Sprintf("%"+flag+string(verb), err), "··", 1)
+		// Traces are elided relative to the trace of the previous link.
+		if i > 0 {
+			ref = innerFrames(ce[i-1])
+		}
+
+		lines := lines.Indent(lines.Sprintf("%"+flag+string(verb), relativeTo{err, ref, policy}), "··", 1)
 
 		errs = append(errs, fmt.Sprintf("··[%d] %s", i, lines[0]))
 
@@ -87,22 +80,49 @@ func (ce ChainError) Format(f fmt.State, verb rune) {
 	f.Write([]byte(strings.Join(errs, "\n")))
 }
 
-// Chain combines errors into a chain of errors. nil errors are removed.
-func Chain(errs ...error) error {
-	ce := ChainError{}
+// MarshalJSON implements json.Marshaler.
+func (ce ChainError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(ce)
+}
 
-	for _, err := range errs {
-		if err == nil {
-			continue
-		}
+// marshalJSON implements policyMarshaler.
+func (ce ChainError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
+	if len(ce) == 0 {
+		return []byte("null"), nil
+	}
 
-		if peer, ok := err.(ChainError); ok {
-			ce = append(ce, peer...)
+	type link struct {
+		Type string          `json:"type"`
+		Err  json.RawMessage `json:"err"`
+	}
 
-			continue
+	output := make([]link, 0, len(ce))
+	for _, e := range ce {
+		ebs, err := errorMarshalJSON(e, policy)
+		if err != nil {
+			return nil, err
 		}
 
-		ce = append(ce, err)
+		output = append(output, link{
+			Type: TypeName(e),
+			Err:  json.RawMessage(ebs),
+		})
+	}
+
+	return json.Marshal(output)
+}
+
+// joinErrorType is the type of the errors returned by errors.Join.
+var joinErrorType = reflect.TypeOf(errors.Join(errors.New("")))
+
+// Chain combines errors into a chain of errors. nil errors are removed. Nested
+// chains and the results of errors.Join are flattened into the chain
+// (including chains and joins nested inside them).
+func Chain(errs ...error) error {
+	ce := ChainError{}
+
+	for _, err := range errs {
+		ce = flatten(ce, err)
 	}
 
 	if len(ce) == 0 {
@@ -116,6 +136,32 @@ func Chain(errs ...error) error {
 	return ce
 }
 
+// flatten appends err to ce. Chains and joins are replaced by their links and
+// nil errors are removed.
+func flatten(ce ChainError, err error) ChainError {
+	if err == nil {
+		return ce
+	}
+
+	if peer, ok := err.(ChainError); ok {
+		for _, link := range peer {
+			ce = flatten(ce, link)
+		}
+
+		return ce
+	}
+
+	if reflect.TypeOf(err) == joinErrorType {
+		for _, link := range err.(multiUnwrapper).Unwrap() {
+			ce = flatten(ce, link)
+		}
+
+		return ce
+	}
+
+	return append(ce, err)
+}
+
 // ChainP combines errors into a chain of errors. nil errors are removed.
 func ChainP(err *error, errs ...error) {
 	if err == nil {
@@ -56,13 +56,16 @@ func opencloseP() (err error) {
 func TestChain(t *testing.T) {
 	t.Run("Chain", func(t *testing.T) {
 		err := openclose()
-		werr := errors.Unwrap(err)
+		werrs := err.(interface{ Unwrap() []error }).Unwrap()
 
 		t.Logf("error: %T\n%+v\n", err, err)
 		require.Error(t, err)
 
-		t.Logf("wrapped error: %T\n%+v\n", werr, werr)
-		require.Error(t, werr)
+		require.Len(t, werrs, 2)
+		for _, werr := range werrs {
+			t.Logf("wrapped error: %T\n%+v\n", werr, werr)
+			require.Error(t, werr)
+		}
 
 		bs, jerr := json.MarshalIndent(err, "", "  ")
 		require.NoError(t, jerr)
@@ -71,13 +74,95 @@ func TestChain(t *testing.T) {
 
 	t.Run("ChainP", func(t *testing.T) {
 		err := opencloseP()
-		werr := errors.Unwrap(err)
+		werrs := err.(interface{ Unwrap() []error }).Unwrap()
 
 		t.Logf("error: %T\n%+v\n", err, err)
 		require.Error(t, err)
 
-		t.Logf("wrapped error: %T\n%+v\n", werr, werr)
-		require.Error(t, werr)
+		require.Len(t, werrs, 2)
+		for _, werr := range werrs {
+			t.Logf("wrapped error: %T\n%+v\n", werr, werr)
+			require.Error(t, werr)
+		}
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		err := oops.Chain(
+			errors.New("first"),
+			oops.Namespace("ns").Wrap(errors.New("second")),
+		)
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+		t.Log(string(bs))
+
+		var links []struct {
+			Type string          `json:"type"`
+			Err  json.RawMessage `json:"err"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &links))
+		require.Len(t, links, 2)
+
+		require.Equal(t, "*errors.errorString", links[0].Type)
+		require.JSONEq(t, `"first"`, string(links[0].Err))
+
+		require.Equal(t, "*oops.NamespaceError", links[1].Type)
+		require.JSONEq(t, `{"name":"ns","type":"*errors.errorString","err":"second"}`, string(links[1].Err))
+
+		bs, jerr = json.Marshal(oops.ChainError{})
+		require.NoError(t, jerr)
+		require.Equal(t, "null", string(bs))
+	})
+
+	t.Run("Is", func(t *testing.T) {
+		e0 := errors.New("0")
+		e1 := errors.New("1")
+		e2 := errors.New("2")
+
+		err := oops.Chain(e0, oops.Trace(e1))
+		require.ErrorIs(t, err, e0)
+		require.ErrorIs(t, err, e1)
+		require.NotErrorIs(t, err, e2)
+	})
+
+	t.Run("As", func(t *testing.T) {
+		cerr := NewCustomError("custom")
+		err := oops.Chain(errors.New("first"), oops.Trace(cerr))
+
+		ce := &CustomError{}
+		require.ErrorAs(t, err, &ce)
+		require.Equal(t, cerr, ce)
+
+		chain := oops.ChainError{}
+		require.ErrorAs(t, err, &chain)
+		require.Equal(t, err, chain)
+	})
+
+	t.Run("Unwrap", func(t *testing.T) {
+		e0 := errors.New("0")
+		e1 := errors.New("1")
+
+		ce := oops.Chain(e0, e1).(oops.ChainError)
+
+		links := ce.Unwrap()
+		links[0] = nil
+
+		require.Equal(t, oops.ChainError{e0, e1}, ce)
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		e0 := errors.New("0")
+		e1 := errors.New("1")
+		e2 := errors.New("2")
+
+		require.Equal(t, oops.ChainError{e0, e1, e2}, oops.Chain(e0, errors.Join(e1, nil, e2)))
+		require.Equal(t, oops.ChainError{e0, e1}, oops.Chain(errors.Join(e0, e1)))
+		require.Equal(t, e0, oops.Chain(errors.Join(e0)))
+
+		e3 := errors.New("3")
+
+		require.Equal(t, oops.ChainError{e0, e1, e2, e3}, oops.Chain(errors.Join(e0, errors.Join(e1, e2)), e3))
+		require.Equal(t, oops.ChainError{e0, e1, e2, e3}, oops.Chain(oops.ChainError{e0, errors.Join(e1, oops.ChainError{e2, e3})}))
 	})
 
 	// Chain should "fold" away nils and remove itself if the chain only
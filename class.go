@@ -0,0 +1,102 @@
+package oops
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is a machine readable error category.
+type Code string
+
+// Common codes. Applications may define their own.
+const (
+	CodeCanceled           Code = "canceled"
+	CodeInvalidArgument    Code = "invalid_argument"
+	CodeDeadlineExceeded   Code = "deadline_exceeded"
+	CodeNotFound           Code = "not_found"
+	CodeAlreadyExists      Code = "already_exists"
+	CodeConflict           Code = "conflict"
+	CodePermissionDenied   Code = "permission_denied"
+	CodeUnauthenticated    Code = "unauthenticated"
+	CodeResourceExhausted  Code = "resource_exhausted"
+	CodeFailedPrecondition Code = "failed_precondition"
+	CodeUnimplemented      Code = "unimplemented"
+	CodeInternal           Code = "internal"
+	CodeUnavailable        Code = "unavailable"
+)
+
+// Class is a code within a namespace. Classes are comparable and can be used
+// as the target of errors.Is to check if an error has the class.
+type Class struct {
+	Namespace Namespace
+	Code      Code
+}
+
+var _ error = Class{}
+
+// Class returns the class for code in the namespace.
+func (n Namespace) Class(code Code) Class {
+	return Class{
+		Namespace: n,
+		Code:      code,
+	}
+}
+
+// Error implements error.
+func (c Class) Error() string {
+	if c.Namespace == "" {
+		return string(c.Code)
+	}
+
+	return string(c.Namespace) + ": " + string(c.Code)
+}
+
+// Wrap returns the error with the class in the class's namespace.
+func (c Class) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	err = &ClassError{
+		Class: c,
+		Err:   err,
+	}
+
+	if c.Namespace == "" {
+		return err
+	}
+
+	return c.Namespace.Wrap(err)
+}
+
+// WrapP replaces the error with one wrapped in the class.
+func (c Class) WrapP(err *error) {
+	if err == nil || *err == nil {
+		return
+	}
+
+	*err = c.Wrap(*err)
+}
+
+// New returns a new error from fmt.Errorf with a stack trace and the class.
+func (c Class) New(format string, a ...any) error {
+	return c.Wrap(TraceN(fmt.Errorf(format, a...), TraceSkipInternal))
+}
+
+// ClassOf returns the class of the first ClassError in err's tree.
+func ClassOf(err error) (Class, bool) {
+	var ce *ClassError
+	if !errors.As(err, &ce) {
+		return Class{}, false
+	}
+
+	return ce.Class, true
+}
+
+// CodeOf returns the code of the first ClassError in err's tree. If there
+// isn't one, then the empty code is returned.
+func CodeOf(err error) Code {
+	c, _ := ClassOf(err)
+
+	return c.Code
+}
@@ -0,0 +1,105 @@
+package oops_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ClassTest = oops.Namespace("class_test")
+
+	ErrNotFound = ClassTest.Class(oops.CodeNotFound)
+	ErrConflict = ClassTest.Class(oops.CodeConflict)
+)
+
+func TestClass(t *testing.T) {
+	t.Run("folding", func(t *testing.T) {
+		require.Nil(t, ErrNotFound.Wrap(nil))
+
+		var err error
+		ErrNotFound.WrapP(&err)
+		require.NoError(t, err)
+	})
+
+	t.Run("New", func(t *testing.T) {
+		err := ErrNotFound.New("missing %q", "key")
+		require.Equal(t, `class_test: missing "key"`, err.Error())
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+		require.Contains(t, output, "class_test: missing \"key\"\n··code: not_found\n")
+
+		ne := err.(*oops.NamespaceError)
+		require.Equal(t, "class_test", ne.Name)
+
+		te := &oops.TraceError{}
+		require.ErrorAs(t, err, &te)
+		require.Equal(t, "github.com/calebcase/oops_test.TestClass.func2", te.Frames()[0].Function)
+	})
+
+	t.Run("Is", func(t *testing.T) {
+		err := oops.Chain(
+			errors.New("first"),
+			oops.With(oops.Trace(ErrNotFound.Wrap(errors.New("missing"))), "k", "v"),
+		)
+
+		require.ErrorIs(t, err, ErrNotFound)
+		require.ErrorIs(t, err, oops.Namespace("class_test").Class(oops.CodeNotFound))
+		require.NotErrorIs(t, err, ErrConflict)
+		require.NotErrorIs(t, err, oops.Namespace("other").Class(oops.CodeNotFound))
+
+		require.Equal(t, oops.CodeNotFound, oops.CodeOf(err))
+
+		c, ok := oops.ClassOf(err)
+		require.True(t, ok)
+		require.Equal(t, ErrNotFound, c)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		require.Equal(t, oops.Code(""), oops.CodeOf(errors.New("plain")))
+		require.Equal(t, oops.Code(""), oops.CodeOf(nil))
+
+		_, ok := oops.ClassOf(errors.New("plain"))
+		require.False(t, ok)
+	})
+
+	t.Run("no namespace", func(t *testing.T) {
+		c := oops.Class{Code: oops.CodeUnavailable}
+		require.Equal(t, "unavailable", c.Error())
+
+		err := c.Wrap(errors.New("down"))
+		require.IsType(t, &oops.ClassError{}, err)
+		require.Equal(t, "down", err.Error())
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		err := ErrConflict.Wrap(errors.New("busy"))
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+		require.JSONEq(t, `{
+			"name": "class_test",
+			"type": "*oops.ClassError",
+			"err": {
+				"namespace": "class_test",
+				"code": "conflict",
+				"type": "*errors.errorString",
+				"err": "busy"
+			},
+			"fingerprint": "`+oops.Fingerprint(err)+`"
+		}`, string(bs))
+
+		bs, jerr = oops.MarshalError(err)
+		require.NoError(t, jerr)
+
+		derr, jerr := oops.UnmarshalError(bs)
+		require.NoError(t, jerr)
+		require.ErrorIs(t, derr, ErrConflict)
+		require.Equal(t, oops.CodeConflict, oops.CodeOf(derr))
+	})
+}
@@ -0,0 +1,109 @@
+package oops
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/calebcase/oops/lines"
+)
+
+// ClassError is an error with a class.
+type ClassError struct {
+	Class Class
+	Err   error
+}
+
+var (
+	_ error     = &ClassError{}
+	_ unwrapper = &ClassError{}
+)
+
+// Error implements error.
+func (ce *ClassError) Error() string {
+	return fmt.Sprintf("%v", ce)
+}
+
+// Unwrap implements the implied interface for errors.Unwrap.
+func (ce *ClassError) Unwrap() error {
+	if ce == nil || ce.Err == nil {
+		return nil
+	}
+
+	return ce.Err
+}
+
+// Is implements the implied interface for errors.Is. It reports whether err
+// is the error's class.
+func (ce *ClassError) Is(err error) bool {
+	if ce == nil {
+		return false
+	}
+
+	c, ok := err.(Class)
+
+	return ok && c == ce.Class
+}
+
+// Format implements fmt.Format.
+func (ce *ClassError) Format(f fmt.State, verb rune) {
+	ce.formatRelative(f, verb, nil, redactPolicy.Load())
+}
+
+// formatRelative implements relativeFormatter.
+func (ce *ClassError) formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy) {
+	if ce == nil || ce.Err == nil {
+		fmt.Fprintf(f, "<nil>")
+
+		return
+	}
+
+	flag := ""
+	if f.Flag(int('+')) {
+		flag = "+"
+	}
+
+	if flag == "" {
+		fmt.Fprintf(f, "%"+string(verb), ce.Err)
+
+		return
+	}
+
+	ls := lines.Sprintf("%"+flag+string(verb), relativeTo{ce.Err, ref, policy})
+
+	output := []string{ls[0], "··code: " + string(ce.Class.Code)}
+	output = append(output, ls[1:]...)
+
+	f.Write([]byte(strings.Join(output, "\n")))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (ce *ClassError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(ce)
+}
+
+// marshalJSON implements policyMarshaler.
+func (ce *ClassError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
+	if ce == nil || ce.Err == nil {
+		return []byte("null"), nil
+	}
+
+	ebs, err := errorMarshalJSON(ce.Err, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	output := struct {
+		Namespace string          `json:"namespace"`
+		Code      string          `json:"code"`
+		Type      string          `json:"type"`
+		Err       json.RawMessage `json:"err"`
+	}{
+		Namespace: string(ce.Class.Namespace),
+		Code:      string(ce.Class.Code),
+		Type:      TypeName(ce.Err),
+		Err:       json.RawMessage(ebs),
+	}
+
+	return json.Marshal(output)
+}
@@ -0,0 +1,96 @@
+package oops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ContextExtractor returns the fields to attach to errors created with a
+// context (e.g. the request ID). It returns nil if ctx doesn't have the
+// values.
+type ContextExtractor func(ctx context.Context) Fields
+
+var (
+	contextExtractorsMu sync.RWMutex
+	contextExtractors   []ContextExtractor
+)
+
+// RegisterContextExtractor adds extractors that are used by NewCtx, TraceCtx,
+// and ContextFields. Fields are attached in the order the extractors were
+// registered.
+func RegisterContextExtractor(es ...ContextExtractor) {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+
+	contextExtractors = append(contextExtractors, es...)
+}
+
+// ContextValue returns an extractor that attaches ctx.Value(key) as the field
+// with the given name. Nothing is attached if the value is nil.
+//
+//	oops.RegisterContextExtractor(oops.ContextValue("request_id", requestIDKey{}))
+func ContextValue(name string, key any) ContextExtractor {
+	return func(ctx context.Context) Fields {
+		value := ctx.Value(key)
+		if value == nil {
+			return nil
+		}
+
+		return Fields{
+			{
+				Key:   name,
+				Value: value,
+			},
+		}
+	}
+}
+
+// ContextFields returns the fields from the registered extractors for ctx.
+func ContextFields(ctx context.Context) (fields Fields) {
+	if ctx == nil {
+		return nil
+	}
+
+	contextExtractorsMu.RLock()
+	defer contextExtractorsMu.RUnlock()
+
+	for _, e := range contextExtractors {
+		fields = append(fields, e(ctx)...)
+	}
+
+	return fields
+}
+
+// withContext attaches the fields from ctx to err.
+func withContext(ctx context.Context, err error) error {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 || err == nil {
+		return err
+	}
+
+	kvs := make([]any, 0, len(fields)*2)
+	for _, f := range fields {
+		kvs = append(kvs, f.Key, f.Value)
+	}
+
+	return With(err, kvs...)
+}
+
+// NewCtx returns a new error from fmt.Errorf with a stack trace and the fields
+// extracted from ctx. The capturer is given ctx if it is a ContextCapturer.
+func NewCtx(ctx context.Context, format string, a ...any) error {
+	return withContext(ctx, TraceWithOptions(fmt.Errorf(format, a...), TraceOptions{
+		Skip:    TraceSkipInternal,
+		Context: ctx,
+	}))
+}
+
+// TraceCtx captures a trace and combines it with err and the fields extracted
+// from ctx. The capturer is given ctx if it is a ContextCapturer.
+func TraceCtx(ctx context.Context, err error) error {
+	return withContext(ctx, TraceWithOptions(err, TraceOptions{
+		Skip:    TraceSkipInternal,
+		Context: ctx,
+	}))
+}
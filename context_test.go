@@ -0,0 +1,132 @@
+package oops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	requestIDKey struct{}
+	tenantKey    struct{}
+)
+
+var ContextTest = oops.Namespace("context")
+
+func init() {
+	oops.RegisterContextExtractor(
+		oops.ContextValue("request_id", requestIDKey{}),
+		oops.ContextValue("tenant", tenantKey{}),
+	)
+}
+
+func contextRequest() context.Context {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+
+	return context.WithValue(ctx, tenantKey{}, "acme")
+}
+
+func TestContextFields(t *testing.T) {
+	require.Equal(t, oops.Fields{
+		{Key: "request_id", Value: "req-1"},
+		{Key: "tenant", Value: "acme"},
+	}, oops.ContextFields(contextRequest()))
+
+	require.Nil(t, oops.ContextFields(context.Background()))
+}
+
+func TestNewCtx(t *testing.T) {
+	type TC struct {
+		Name     string
+		Err      error
+		Function string
+	}
+
+	ctx := contextRequest()
+
+	tcs := []TC{
+		{
+			Name:     "NewCtx",
+			Err:      oops.NewCtx(ctx, "bad stuff: %d", 42),
+			Function: "github.com/calebcase/oops_test.TestNewCtx",
+		},
+		{
+			Name:     "TraceCtx",
+			Err:      oops.TraceCtx(ctx, errors.New("bad stuff: 42")),
+			Function: "github.com/calebcase/oops_test.TestNewCtx",
+		},
+		{
+			Name:     "Namespace.NewCtx",
+			Err:      ContextTest.NewCtx(ctx, "bad stuff: %d", 42),
+			Function: "github.com/calebcase/oops_test.TestNewCtx",
+		},
+		{
+			Name:     "Namespace.TraceCtx",
+			Err:      ContextTest.TraceCtx(ctx, errors.New("bad stuff: 42")),
+			Function: "github.com/calebcase/oops_test.TestNewCtx",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Contains(t, tc.Err.Error(), "bad stuff: 42")
+
+			require.Equal(t, oops.Fields{
+				{Key: "request_id", Value: "req-1"},
+				{Key: "tenant", Value: "acme"},
+			}, oops.FieldsOf(tc.Err))
+
+			te := &oops.TraceError{}
+			require.ErrorAs(t, tc.Err, &te)
+			require.Equal(t, tc.Function, te.Frames()[0].Function)
+
+			output := fmt.Sprintf("%+v", tc.Err)
+			require.Contains(t, output, "fields:")
+			require.Contains(t, output, "request_id: req-1")
+			require.Contains(t, output, "tenant: acme")
+
+			bs, err := json.Marshal(tc.Err)
+			require.NoError(t, err)
+			require.Contains(t, string(bs), `"fields":{"request_id":"req-1","tenant":"acme"}`)
+		})
+	}
+
+	t.Run("ContextCapturer", func(t *testing.T) {
+		oops.SetDefaultCapturer(oops.CaptureGoroutine())
+		defer oops.SetDefaultCapturer(oops.CaptureFunc[oops.Frames](oops.CaptureFrames))
+
+		var errs []error
+		pprof.Do(ctx, pprof.Labels("task", "abc"), func(ctx context.Context) {
+			errs = append(errs,
+				oops.NewCtx(ctx, "bad stuff"),
+				oops.TraceCtx(ctx, errors.New("bad stuff")),
+				ContextTest.NewCtx(ctx, "bad stuff"),
+				ContextTest.TraceCtx(ctx, errors.New("bad stuff")),
+			)
+		})
+
+		for _, err := range errs {
+			te := &oops.TraceError{}
+			require.ErrorAs(t, err, &te)
+
+			gf, ok := te.Data.(oops.GoroutineFrames)
+			require.True(t, ok)
+			require.Equal(t, "github.com/calebcase/oops_test.TestNewCtx.func2.1", gf.Frames[0].Function)
+			require.Equal(t, map[string]string{"task": "abc"}, gf.Labels)
+		}
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		err := oops.NewCtx(context.Background(), "bad stuff")
+
+		_, ok := err.(*oops.TraceError)
+		require.True(t, ok)
+		require.Nil(t, oops.FieldsOf(err))
+	})
+}
@@ -85,7 +85,7 @@ func TestCustomError(t *testing.T) {
 		require.ErrorAs(t, err, &ce)
 
 		require.Equal(t, cerr, ce)
-		require.Equal(t, oops.ChainError{cerr}, errors.Unwrap(err))
-		require.Equal(t, cerr, errors.Unwrap(errors.Unwrap(err)))
+		require.Nil(t, errors.Unwrap(err))
+		require.Equal(t, []error{first, cerr}, err.(interface{ Unwrap() []error }).Unwrap())
 	})
 }
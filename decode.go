@@ -0,0 +1,499 @@
+package oops
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// Decoder rebuilds an error from the JSON produced by ErrorMarshalJSON.
+type Decoder func(data json.RawMessage) (error, error)
+
+// TraceDecoder rebuilds trace data from the decoded frames and the extra
+// fields of a TraceError (e.g. those added by a TraceMarshaler). It returns
+// false if the fields aren't for the data it rebuilds.
+type TraceDecoder func(fs Frames, extra map[string]json.RawMessage) (data any, ok bool)
+
+// ErrHidden stands in for the hidden error of a decoded ShadowError. The
+// hidden error is never marshalled so it cannot be recovered.
+var ErrHidden = errors.New("hidden")
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{}
+
+	traceDecodersMu sync.RWMutex
+	traceDecoders   []TraceDecoder
+
+	sentinelsMu sync.RWMutex
+	sentinels   = map[sentinelKey][]error{}
+)
+
+// sentinelKey identifies a sentinel by its type and message.
+type sentinelKey struct {
+	Type    string
+	Message string
+}
+
+func init() {
+	RegisterDecoder(fmt.Sprintf("%T", &TraceError{}), decodeTraceError)
+	RegisterDecoder(fmt.Sprintf("%T", &NamespaceError{}), decodeNamespaceError)
+	RegisterDecoder(fmt.Sprintf("%T", &ShadowError{}), decodeShadowError)
+	RegisterDecoder(fmt.Sprintf("%T", ChainError{}), decodeChainError)
+	RegisterDecoder(fmt.Sprintf("%T", &FieldsError{}), decodeFieldsError)
+	RegisterDecoder(fmt.Sprintf("%T", &ClassError{}), decodeClassError)
+	RegisterDecoder(fmt.Sprintf("%T", &PanicError{}), decodePanicError)
+
+	RegisterTraceDecoder(decodeGoroutineFrames)
+}
+
+// RegisterDecoder sets the decoder used for errors of the given type. The
+// type is the name as formatted by %T (e.g. "*fs.PathError").
+func RegisterDecoder(typ string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[typ] = d
+}
+
+// RegisterTraceDecoder adds decoders for trace data with extra fields. The
+// decoders are tried in the order they were registered.
+func RegisterTraceDecoder(ds ...TraceDecoder) {
+	traceDecodersMu.Lock()
+	defer traceDecodersMu.Unlock()
+
+	traceDecoders = append(traceDecoders, ds...)
+}
+
+// RegisterSentinel registers sentinel errors (e.g. io.EOF) so that decoded
+// errors with the same type and message match them with errors.Is and
+// errors.As.
+func RegisterSentinel(errs ...error) {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		key := sentinelKey{
+			Type:    fmt.Sprintf("%T", err),
+			Message: err.Error(),
+		}
+
+		sentinels[key] = append(sentinels[key], err)
+	}
+}
+
+// MarshalError marshals err with its type and fingerprint (see Fingerprint)
+// into the envelope read by UnmarshalError.
+func MarshalError(err error) (bs []byte, merr error) {
+	if err == nil {
+		return []byte("null"), nil
+	}
+
+	ebs, merr := errorMarshalJSON(err, redactPolicy.Load())
+	if merr != nil {
+		return nil, merr
+	}
+
+	return json.Marshal(envelope{
+		Type:        TypeName(err),
+		Err:         json.RawMessage(ebs),
+		Fingerprint: Fingerprint(err),
+	})
+}
+
+// UnmarshalError rebuilds an error from the envelope created by MarshalError.
+// The JSON produced by marshalling a TraceError, NamespaceError, ChainError,
+// FieldsError, ClassError, or PanicError directly (e.g. with json.Marshal) is also
+// accepted. A ShadowError marshalled directly has the same shape as the
+// envelope and is rebuilt as its public error. Other types use the decoder
+// registered for them and fall back to OpaqueError.
+func UnmarshalError(data []byte) (error, error) {
+	if typ := shapeType(data); typ != "" {
+		return decodeError(typ, data)
+	}
+
+	var output envelope
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	return decodeError(output.Type, output.Err)
+}
+
+// shapes are the keys that only appear in the JSON of a wrapper and the type
+// of the wrapper.
+var shapes = []struct {
+	Key  string
+	Type string
+}{
+	{"data", fmt.Sprintf("%T", &TraceError{})},
+	{"name", fmt.Sprintf("%T", &NamespaceError{})},
+	{"fields", fmt.Sprintf("%T", &FieldsError{})},
+	{"code", fmt.Sprintf("%T", &ClassError{})},
+	{"value_type", fmt.Sprintf("%T", &PanicError{})},
+}
+
+// shapeType returns the type of the wrapper whose MarshalJSON produced data.
+// If data is an envelope (or isn't valid), then an empty string is returned.
+func shapeType(data []byte) string {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return fmt.Sprintf("%T", ChainError{})
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return ""
+	}
+
+	for _, s := range shapes {
+		if _, ok := keys[s.Key]; ok {
+			return s.Type
+		}
+	}
+
+	return ""
+}
+
+// decodeError decodes data using the decoder registered for typ.
+func decodeError(typ string, data json.RawMessage) (error, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	decodersMu.RLock()
+	d, ok := decoders[typ]
+	decodersMu.RUnlock()
+
+	if ok {
+		return d(data)
+	}
+
+	oe := &OpaqueError{
+		Type: typ,
+	}
+
+	if err := json.Unmarshal(data, &oe.Message); err != nil {
+		oe.Message = string(data)
+	}
+
+	return oe, nil
+}
+
+// envelope is the common {"type", "err"} shape used by the wrappers. The
+// fingerprint is only set at the top of a tree.
+type envelope struct {
+	Type        string          `json:"type"`
+	Err         json.RawMessage `json:"err"`
+	Fingerprint string          `json:"fingerprint,omitempty"`
+}
+
+func decodeTraceError(data json.RawMessage) (error, error) {
+	var output struct {
+		envelope
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	// The remaining fields were added by a TraceMarshaler.
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return nil, err
+	}
+
+	for _, k := range []string{"type", "err", "fingerprint", "data"} {
+		delete(extra, k)
+	}
+
+	err, derr := decodeError(output.Type, output.Err)
+	if derr != nil || err == nil {
+		return nil, derr
+	}
+
+	return &TraceError{
+		Data: decodeTraceData(output.Data, extra),
+		Err:  err,
+	}, nil
+}
+
+// decodeTraceData decodes trace data as Frames when possible and otherwise
+// keeps the raw JSON. If there are extra fields, then the frames are given to
+// the registered trace decoders.
+func decodeTraceData(data json.RawMessage, extra map[string]json.RawMessage) any {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	fs, ok := decodeFrames(data)
+	if !ok {
+		return data
+	}
+
+	if len(extra) == 0 {
+		return fs
+	}
+
+	traceDecodersMu.RLock()
+	defer traceDecodersMu.RUnlock()
+
+	for _, d := range traceDecoders {
+		if v, ok := d(fs, extra); ok {
+			return v
+		}
+	}
+
+	return fs
+}
+
+// decodeFrames decodes data as Frames. It returns false if data isn't frames.
+func decodeFrames(data json.RawMessage) (Frames, bool) {
+	var frames []struct {
+		PC       uintptr
+		Function string
+		File     string
+		Line     int
+		Entry    uintptr
+	}
+
+	if err := json.Unmarshal(data, &frames); err != nil {
+		return nil, false
+	}
+
+	fs := make(Frames, 0, len(frames))
+	for _, f := range frames {
+		if f.Function == "" && f.File == "" {
+			return nil, false
+		}
+
+		fs = append(fs, runtime.Frame{
+			PC:       f.PC,
+			Function: f.Function,
+			File:     f.File,
+			Line:     f.Line,
+			Entry:    f.Entry,
+		})
+	}
+
+	return fs, true
+}
+
+func decodeNamespaceError(data json.RawMessage) (error, error) {
+	var output struct {
+		Name string `json:"name"`
+		envelope
+	}
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	err, derr := decodeError(output.Type, output.Err)
+	if derr != nil || err == nil {
+		return nil, derr
+	}
+
+	return &NamespaceError{
+		Name: output.Name,
+		Err:  err,
+	}, nil
+}
+
+func decodeShadowError(data json.RawMessage) (error, error) {
+	var output envelope
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	err, derr := decodeError(output.Type, output.Err)
+	if derr != nil || err == nil {
+		return nil, derr
+	}
+
+	return &ShadowError{
+		Hidden: ErrHidden,
+		Err:    err,
+	}, nil
+}
+
+func decodeChainError(data json.RawMessage) (error, error) {
+	var output []envelope
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	ce := make(ChainError, 0, len(output))
+	for _, link := range output {
+		err, derr := decodeError(link.Type, link.Err)
+		if derr != nil {
+			return nil, derr
+		}
+
+		if err == nil {
+			continue
+		}
+
+		ce = append(ce, err)
+	}
+
+	if len(ce) == 0 {
+		return nil, nil
+	}
+
+	return ce, nil
+}
+
+func decodeFieldsError(data json.RawMessage) (error, error) {
+	var output struct {
+		envelope
+		Fields Fields `json:"fields"`
+	}
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	err, derr := decodeError(output.Type, output.Err)
+	if derr != nil || err == nil {
+		return nil, derr
+	}
+
+	return &FieldsError{
+		Fields: output.Fields,
+		Err:    err,
+	}, nil
+}
+
+func decodeClassError(data json.RawMessage) (error, error) {
+	var output struct {
+		Namespace string `json:"namespace"`
+		Code      string `json:"code"`
+		envelope
+	}
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	err, derr := decodeError(output.Type, output.Err)
+	if derr != nil || err == nil {
+		return nil, derr
+	}
+
+	return &ClassError{
+		Class: Class{
+			Namespace: Namespace(output.Namespace),
+			Code:      Code(output.Code),
+		},
+		Err: err,
+	}, nil
+}
+
+func decodePanicError(data json.RawMessage) (error, error) {
+	var output struct {
+		Value string `json:"value"`
+		envelope
+	}
+
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+
+	if len(output.Err) == 0 {
+		// The original value isn't an error. Only its formatted value
+		// is kept.
+		return &PanicError{
+			Value: output.Value,
+		}, nil
+	}
+
+	err, derr := decodeError(output.Type, output.Err)
+	if derr != nil || err == nil {
+		return nil, derr
+	}
+
+	return &PanicError{
+		Value: err,
+	}, nil
+}
+
+// OpaqueError is a decoded error for which no decoder was registered. It
+// matches registered sentinels with the same type and message.
+type OpaqueError struct {
+	Type    string
+	Message string
+}
+
+var _ error = &OpaqueError{}
+
+// Error implements error.
+func (oe *OpaqueError) Error() string {
+	if oe == nil {
+		return ""
+	}
+
+	return oe.Message
+}
+
+// sentinels returns the registered sentinels matching the type and message.
+func (oe *OpaqueError) sentinels() []error {
+	if oe == nil {
+		return nil
+	}
+
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+
+	return sentinels[sentinelKey{
+		Type:    oe.Type,
+		Message: oe.Message,
+	}]
+}
+
+// Is implements the implied interface for errors.Is.
+func (oe *OpaqueError) Is(target error) bool {
+	if target == nil || !reflect.TypeOf(target).Comparable() {
+		return false
+	}
+
+	for _, s := range oe.sentinels() {
+		if reflect.TypeOf(s).Comparable() && s == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As implements the implied interface for errors.As.
+func (oe *OpaqueError) As(target any) bool {
+	for _, s := range oe.sentinels() {
+		if errors.As(s, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON implements json.Marshaler.
+func (oe *OpaqueError) MarshalJSON() ([]byte, error) {
+	if oe == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(oe.Message)
+}
@@ -0,0 +1,191 @@
+package oops_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+var ErrDecodeSentinel = errors.New("decode sentinel")
+
+func init() {
+	oops.RegisterSentinel(ErrDecodeSentinel, io.EOF)
+}
+
+func TestUnmarshalError(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		bs, err := oops.MarshalError(nil)
+		require.NoError(t, err)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+		require.Nil(t, derr)
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		orig := oops.Trace(ErrDecodeSentinel)
+
+		bs, err := oops.MarshalError(orig)
+		require.NoError(t, err)
+		t.Log(string(bs))
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+		t.Logf("%+v", derr)
+
+		require.Equal(t, orig.Error(), derr.Error())
+		require.ErrorIs(t, derr, ErrDecodeSentinel)
+
+		ote := orig.(*oops.TraceError)
+		dte := derr.(*oops.TraceError)
+
+		ofs := ote.Data.(oops.Frames)
+		dfs := dte.Data.(oops.Frames)
+		require.Len(t, dfs, len(ofs))
+
+		for i := range ofs {
+			require.Equal(t, ofs[i].Function, dfs[i].Function)
+			require.Equal(t, ofs[i].File, dfs[i].File)
+			require.Equal(t, ofs[i].Line, dfs[i].Line)
+		}
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		ns := oops.Namespace("decode")
+		orig := ns.Chain(
+			oops.Shadow(errors.New("internal"), io.EOF),
+			oops.New("close: %w", ErrDecodeSentinel),
+		)
+
+		bs, err := oops.MarshalError(orig)
+		require.NoError(t, err)
+		t.Log(string(bs))
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+		t.Logf("%+v", derr)
+
+		require.Equal(t, orig.Error(), derr.Error())
+		require.ErrorIs(t, derr, io.EOF)
+
+		ne := &oops.NamespaceError{}
+		require.ErrorAs(t, derr, &ne)
+		require.Equal(t, "decode", ne.Name)
+
+		ce, ok := ne.Err.(oops.ChainError)
+		require.True(t, ok)
+		require.Len(t, ce, 2)
+
+		se, ok := ce[0].(*oops.ShadowError)
+		require.True(t, ok)
+		require.Equal(t, oops.ErrHidden, se.Hidden)
+
+		// The wrapped sentinel is flattened into its message by
+		// fmt.Errorf so it cannot be matched.
+		require.NotErrorIs(t, ce[1], ErrDecodeSentinel)
+
+		// Decoded errors can be relayed again.
+		dbs, err := oops.MarshalError(derr)
+		require.NoError(t, err)
+
+		rerr, err := oops.UnmarshalError(dbs)
+		require.NoError(t, err)
+		require.Equal(t, derr, rerr)
+	})
+
+	t.Run("opaque", func(t *testing.T) {
+		orig := oops.Trace(&fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist})
+
+		bs, err := oops.MarshalError(orig)
+		require.NoError(t, err)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+
+		oe := &oops.OpaqueError{}
+		require.ErrorAs(t, derr, &oe)
+		require.Equal(t, "*fs.PathError", oe.Type)
+		require.Equal(t, "open x: file does not exist", oe.Message)
+
+		pe := &fs.PathError{}
+		require.False(t, errors.As(derr, &pe))
+	})
+
+	t.Run("as", func(t *testing.T) {
+		bs, err := oops.MarshalError(oops.Trace(NewCustomError("registered")))
+		require.NoError(t, err)
+
+		sentinel := NewCustomError("registered")
+		oops.RegisterSentinel(sentinel)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+
+		ce := &CustomError{}
+		require.ErrorAs(t, derr, &ce)
+		require.Equal(t, sentinel, ce)
+	})
+
+	t.Run("decoder", func(t *testing.T) {
+		oops.RegisterDecoder("*oops_test.decodedError", func(data json.RawMessage) (error, error) {
+			return &decodedError{string(data)}, nil
+		})
+
+		bs, err := oops.MarshalError(oops.Trace(&decodedError{"x"}))
+		require.NoError(t, err)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+
+		de := &decodedError{}
+		require.ErrorAs(t, derr, &de)
+		require.Equal(t, `"x"`, de.msg)
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		ns := oops.Namespace("decode")
+		origs := []error{
+			oops.Trace(ErrDecodeSentinel),
+			ns.New("plain: %w", io.EOF),
+			oops.Chain(io.EOF, ErrDecodeSentinel),
+			oops.With(oops.Trace(io.EOF), "key", "value"),
+			oops.Class{Code: oops.CodeNotFound}.Wrap(io.EOF),
+		}
+
+		for _, orig := range origs {
+			bs, err := json.Marshal(orig)
+			require.NoError(t, err)
+
+			derr, err := oops.UnmarshalError(bs)
+			require.NoError(t, err)
+			require.IsType(t, orig, derr, string(bs))
+			require.Equal(t, orig.Error(), derr.Error())
+		}
+
+		bs, err := json.Marshal(oops.With(io.EOF, "key", "value"))
+		require.NoError(t, err)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+		require.ErrorIs(t, derr, io.EOF)
+		require.Equal(t, oops.Fields{{Key: "key", Value: "value"}}, oops.FieldsOf(derr))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := oops.UnmarshalError([]byte(`{`))
+		require.Error(t, err)
+	})
+}
+
+type decodedError struct {
+	msg string
+}
+
+func (de *decodedError) Error() string {
+	return de.msg
+}
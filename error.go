@@ -2,11 +2,17 @@ package oops
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // ErrorMarshalJSON uses e's json.Marshaler if it implements one otherwise it
-// uses the output from e.Error() (marshalled into a JSON string).
+// uses the output from e.Error() (marshalled into a JSON string). A nil error
+// is marshalled as null.
 func ErrorMarshalJSON(e error) (bs []byte, err error) {
+	if e == nil {
+		return []byte("null"), nil
+	}
+
 	if jm, ok := e.(json.Marshaler); ok {
 		bs, err = jm.MarshalJSON()
 	} else {
@@ -19,3 +25,112 @@ func ErrorMarshalJSON(e error) (bs []byte, err error) {
 
 	return bs, nil
 }
+
+// policyMarshaler is implemented by errors that pass the redaction policy
+// down to the errors they wrap when they are marshalled.
+type policyMarshaler interface {
+	marshalJSON(policy *RedactPolicy) ([]byte, error)
+}
+
+// errorMarshalJSON is ErrorMarshalJSON with the redaction policy passed down
+// to the errors that support it.
+func errorMarshalJSON(e error, policy *RedactPolicy) (bs []byte, err error) {
+	if pm, ok := e.(policyMarshaler); ok {
+		return pm.marshalJSON(policy)
+	}
+
+	return ErrorMarshalJSON(e)
+}
+
+// topMarshalJSON marshals e as the top of an error tree. The package level
+// redaction policy is used and the fingerprint is included.
+func topMarshalJSON(e error) (bs []byte, err error) {
+	bs, err = errorMarshalJSON(e, redactPolicy.Load())
+	if err != nil {
+		return nil, err
+	}
+
+	return withFingerprint(bs, e), nil
+}
+
+// joinObjects returns the JSON object with the fields of both objects a and
+// b.
+func joinObjects(a, b []byte) []byte {
+	if len(b) <= 2 {
+		return a
+	}
+
+	if len(a) <= 2 {
+		return b
+	}
+
+	output := make([]byte, 0, len(a)+len(b))
+	output = append(output, a[:len(a)-1]...)
+	output = append(output, ',')
+	output = append(output, b[1:]...)
+
+	return output
+}
+
+// TypeName returns the type name of e as formatted by %T (e.g.
+// "*fs.PathError"). Decoded OpaqueErrors report the type of the original
+// error and RedactErrors report the type of the error they wrap. This is the
+// type used in the JSON output and by Fingerprint.
+func TypeName(e error) string {
+	if oe, ok := e.(*OpaqueError); ok && oe != nil {
+		return oe.Type
+	}
+
+	if re, ok := e.(*RedactError); ok && re != nil && re.Err != nil {
+		return TypeName(re.Err)
+	}
+
+	return fmt.Sprintf("%T", e)
+}
+
+// Base returns the error below the oops wrappers: TraceError, NamespaceError,
+// ClassError, FieldsError, VerboseError, RedactError, and ShadowError (whose
+// public error is used). Other errors (including ChainErrors) are returned as
+// is.
+func Base(err error) error {
+	for {
+		var next error
+
+		switch e := err.(type) {
+		case *TraceError:
+			if e != nil {
+				next = e.Err
+			}
+		case *NamespaceError:
+			if e != nil {
+				next = e.Err
+			}
+		case *ClassError:
+			if e != nil {
+				next = e.Err
+			}
+		case *FieldsError:
+			if e != nil {
+				next = e.Err
+			}
+		case *VerboseError:
+			if e != nil {
+				next = e.Err
+			}
+		case *RedactError:
+			if e != nil {
+				next = e.Err
+			}
+		case *ShadowError:
+			if e != nil {
+				next = e.Err
+			}
+		}
+
+		if next == nil {
+			return err
+		}
+
+		err = next
+	}
+}
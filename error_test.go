@@ -0,0 +1,26 @@
+package oops_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase(t *testing.T) {
+	ns := oops.Namespace("base")
+
+	require.Equal(t, io.EOF, oops.Base(io.EOF))
+	require.Equal(t, io.EOF, oops.Base(ns.Trace(oops.With(io.EOF, "key", "value"))))
+	require.Equal(t, io.EOF, oops.Base(oops.Shadow(errors.New("hidden"), oops.Trace(io.EOF))))
+	require.Equal(t, io.EOF, oops.Base(oops.Redact(oops.Verbose(io.EOF), oops.RedactPolicy{})))
+	require.Equal(t, io.EOF, oops.Base(oops.Class{Code: oops.CodeNotFound}.Wrap(io.EOF)))
+
+	ce := oops.Chain(io.EOF, io.ErrUnexpectedEOF)
+	require.Equal(t, ce, oops.Base(ns.Wrap(ce)))
+
+	require.Nil(t, oops.Base(nil))
+	require.Equal(t, &oops.TraceError{}, oops.Base(&oops.TraceError{}))
+}
@@ -0,0 +1,307 @@
+package oops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/calebcase/oops/lines"
+)
+
+// FieldsBadKey is the key used for values that are missing a key.
+const FieldsBadKey = "!BADKEY"
+
+// Field is a key/value pair attached to an error.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Fields is an ordered list of fields.
+type Fields []Field
+
+// MarshalJSON implements json.Marshaler. Fields are marshalled as an object
+// with the keys in order. If a key is repeated the last value (the one added
+// closest to the caller) is used at the position of the first. The values are
+// included according to the redaction policy.
+func (fs Fields) MarshalJSON() ([]byte, error) {
+	return fs.marshalJSON(redactPolicy.Load())
+}
+
+// marshalJSON marshals the fields with the redaction policy.
+func (fs Fields) marshalJSON(policy *RedactPolicy) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	last := make(map[string]int, len(fs))
+	for i, f := range fs {
+		last[f.Key] = i
+	}
+
+	written := 0
+
+	buf.WriteByte('{')
+	for _, f := range fs {
+		i, ok := last[f.Key]
+		if !ok {
+			// Already written.
+			continue
+		}
+		delete(last, f.Key)
+
+		var value any
+
+		switch policy.field(f.Key) {
+		case RedactRender:
+			value = redactValue(fs[i].Value, policy)
+		case RedactMask:
+			value = Redacted
+		default:
+			continue
+		}
+
+		kbs, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		// Values that can't be marshalled (e.g. channels, functions, or
+		// cycles) are formatted instead so the rest of the error is kept.
+		vbs, err := json.Marshal(value)
+		if err != nil {
+			vbs, err = json.Marshal(fmt.Sprintf("%+v", value))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		written++
+
+		buf.Write(kbs)
+		buf.WriteByte(':')
+		buf.Write(vbs)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The order of the keys is kept.
+func (fs *Fields) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok == nil {
+		*fs = nil
+
+		return nil
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("oops: fields must be a JSON object: %s", data)
+	}
+
+	output := Fields{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+
+		output = append(output, Field{
+			Key:   tok.(string),
+			Value: value,
+		})
+	}
+
+	*fs = output
+
+	return nil
+}
+
+// FieldsError is an error with structured fields.
+type FieldsError struct {
+	Fields Fields
+	Err    error
+}
+
+var (
+	_ error     = &FieldsError{}
+	_ unwrapper = &FieldsError{}
+)
+
+// Error implements error.
+func (fe *FieldsError) Error() string {
+	return fmt.Sprintf("%v", fe)
+}
+
+// Unwrap implements the implied interface for errors.Unwrap.
+func (fe *FieldsError) Unwrap() error {
+	if fe == nil || fe.Err == nil {
+		return nil
+	}
+
+	return fe.Err
+}
+
+// Format implements fmt.Format. With %+v the fields are included according
+// to the redaction policy.
+func (fe *FieldsError) Format(f fmt.State, verb rune) {
+	fe.formatRelative(f, verb, nil, redactPolicy.Load())
+}
+
+// formatRelative implements relativeFormatter.
+func (fe *FieldsError) formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy) {
+	if fe == nil || fe.Err == nil {
+		fmt.Fprintf(f, "<nil>")
+
+		return
+	}
+
+	flag := ""
+	if f.Flag(int('+')) {
+		flag = "+"
+	}
+
+	if flag == "" {
+		fmt.Fprintf(f, "%"+string(verb), fe.Err)
+
+		return
+	}
+
+	output := lines.Indent(lines.Sprintf("%"+flag+string(verb), relativeTo{fe.Err, ref, policy}), "··", 1)
+	output = append(output, "··fields:")
+
+	for _, field := range fe.Fields {
+		var ls []string
+
+		switch policy.field(field.Key) {
+		case RedactRender:
+			ls = lines.Sprintf("%s: %"+flag+string(verb), field.Key, redactValue(field.Value, policy))
+		case RedactMask:
+			ls = []string{field.Key + ": " + Redacted}
+		default:
+			continue
+		}
+
+		output = append(output, lines.Indent(ls, "····", 0)...)
+	}
+
+	f.Write([]byte(strings.Join(output, "\n")))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (fe *FieldsError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(fe)
+}
+
+// marshalJSON implements policyMarshaler.
+func (fe *FieldsError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
+	if fe == nil || fe.Err == nil {
+		return []byte("null"), nil
+	}
+
+	ebs, err := errorMarshalJSON(fe.Err, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	fbs, err := fe.Fields.marshalJSON(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	output := struct {
+		Type   string          `json:"type"`
+		Err    json.RawMessage `json:"err"`
+		Fields json.RawMessage `json:"fields"`
+	}{
+		Type:   TypeName(fe.Err),
+		Err:    json.RawMessage(ebs),
+		Fields: json.RawMessage(fbs),
+	}
+
+	return json.Marshal(output)
+}
+
+// With attaches the key/value pairs to err. Keys should be strings; a value
+// without a string key is recorded under FieldsBadKey. If err already has
+// fields at the top, then the new fields are added after them.
+func With(err error, kvs ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	fields := Fields{}
+	for len(kvs) > 0 {
+		key, ok := kvs[0].(string)
+		if !ok || len(kvs) == 1 {
+			fields = append(fields, Field{
+				Key:   FieldsBadKey,
+				Value: kvs[0],
+			})
+			kvs = kvs[1:]
+
+			continue
+		}
+
+		fields = append(fields, Field{
+			Key:   key,
+			Value: kvs[1],
+		})
+		kvs = kvs[2:]
+	}
+
+	if fe, ok := err.(*FieldsError); ok && fe != nil {
+		return &FieldsError{
+			Fields: append(append(Fields{}, fe.Fields...), fields...),
+			Err:    fe.Err,
+		}
+	}
+
+	return &FieldsError{
+		Fields: fields,
+		Err:    err,
+	}
+}
+
+// WithP replaces err with one that has the key/value pairs attached.
+func WithP(err *error, kvs ...any) {
+	if err == nil || *err == nil {
+		return
+	}
+
+	*err = With(*err, kvs...)
+}
+
+// FieldsOf returns the fields of every FieldsError in err's tree. The tree is
+// searched depth first, including every link of a ChainError, and inner fields
+// come before outer fields (so the fields closest to the caller win when the
+// result is marshalled).
+func FieldsOf(err error) (fields Fields) {
+	if err == nil {
+		return nil
+	}
+
+	for _, e := range UnwrapAll(err) {
+		fields = append(fields, FieldsOf(e)...)
+	}
+
+	if fe, ok := err.(*FieldsError); ok && fe != nil {
+		fields = append(fields, fe.Fields...)
+	}
+
+	return fields
+}
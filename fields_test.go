@@ -0,0 +1,131 @@
+package oops_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWith(t *testing.T) {
+	t.Run("folding", func(t *testing.T) {
+		require.Nil(t, oops.With(nil, "a", 1))
+		require.NoError(t, oops.With(nil, "a", 1))
+
+		var err error
+		oops.WithP(&err, "a", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("build up", func(t *testing.T) {
+		base := errors.New("bad stuff")
+
+		err := oops.With(base, "user_id", 42)
+		err = oops.With(err, "shard", 3, "odd")
+
+		fe := err.(*oops.FieldsError)
+		require.Equal(t, base, fe.Err)
+		require.Equal(t, oops.Fields{
+			{Key: "user_id", Value: 42},
+			{Key: "shard", Value: 3},
+			{Key: oops.FieldsBadKey, Value: "odd"},
+		}, fe.Fields)
+
+		require.Equal(t, "bad stuff", err.Error())
+		require.ErrorIs(t, err, base)
+	})
+
+	t.Run("FieldsOf", func(t *testing.T) {
+		ns := oops.Namespace("fields")
+
+		err := oops.With(
+			ns.Wrap(oops.Chain(
+				oops.With(oops.New("first"), "link", 0),
+				oops.Trace(oops.With(errors.New("second"), "link", 1)),
+			)),
+			"request", "abc",
+		)
+
+		require.Equal(t, oops.Fields{
+			{Key: "link", Value: 0},
+			{Key: "link", Value: 1},
+			{Key: "request", Value: "abc"},
+		}, oops.FieldsOf(err))
+
+		require.Nil(t, oops.FieldsOf(errors.New("plain")))
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		err := oops.With(oops.New("bad stuff"), "user_id", 42, "name", "a\nb")
+
+		require.Equal(t, "bad stuff", fmt.Sprintf("%v", err))
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+		require.Contains(t, output, "··fields:\n····user_id: 42\n····name: a\n····b")
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		err := oops.With(errors.New("bad stuff"), "b", 1, "a", "x", "b", 2)
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+		require.Equal(t, `{"type":"*errors.errorString","err":"bad stuff","fields":{"b":2,"a":"x"},"fingerprint":"`+oops.Fingerprint(err)+`"}`, string(bs))
+
+		bs, jerr = oops.MarshalError(err)
+		require.NoError(t, jerr)
+
+		derr, jerr := oops.UnmarshalError(bs)
+		require.NoError(t, jerr)
+		require.Equal(t, oops.Fields{
+			{Key: "b", Value: float64(2)},
+			{Key: "a", Value: "x"},
+		}, oops.FieldsOf(derr))
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		err := oops.With(oops.With(errors.New("bad stuff"), "user", "inner", "id", 1), "user", "outer")
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+		require.Equal(t, `{"type":"*errors.errorString","err":"bad stuff","fields":{"user":"outer","id":1},"fingerprint":"`+oops.Fingerprint(err)+`"}`, string(bs))
+
+		err = oops.With(oops.Trace(oops.With(errors.New("bad stuff"), "user", "inner")), "user", "outer")
+
+		bs, jerr = json.Marshal(oops.FieldsOf(err))
+		require.NoError(t, jerr)
+		require.Equal(t, `{"user":"outer"}`, string(bs))
+	})
+	t.Run("unmarshalable values", func(t *testing.T) {
+		type node struct {
+			Next *node
+		}
+
+		cycle := &node{}
+		cycle.Next = cycle
+
+		err := oops.With(errors.New("bad stuff"), "ch", make(chan int), "fn", func() {}, "cycle", cycle, "ok", 1)
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+
+		var decoded struct {
+			Fields map[string]any `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &decoded))
+		require.IsType(t, "", decoded.Fields["ch"])
+		require.IsType(t, "", decoded.Fields["fn"])
+		require.IsType(t, "", decoded.Fields["cycle"])
+		require.Equal(t, float64(1), decoded.Fields["ok"])
+
+		bs, jerr = oops.MarshalError(oops.Trace(err))
+		require.NoError(t, jerr)
+
+		derr, jerr := oops.UnmarshalError(bs)
+		require.NoError(t, jerr)
+		require.Equal(t, "bad stuff", derr.Error())
+	})
+}
@@ -0,0 +1,163 @@
+package oops
+
+import (
+	"path"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// FrameFilter transforms frames (e.g. removing the frames that aren't of
+// interest).
+type FrameFilter func(fs Frames) Frames
+
+// Filter returns the frames after applying the filters in order.
+func (fs Frames) Filter(filters ...FrameFilter) Frames {
+	for _, filter := range filters {
+		fs = filter(fs)
+	}
+
+	return fs
+}
+
+// FramePackage returns the package path of the frame's function (e.g.
+// "net/http" for "net/http.(*conn).serve").
+func FramePackage(f runtime.Frame) string {
+	return framePackage(f.Function)
+}
+
+// framePackage returns the package path of the function.
+func framePackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if slash < 0 {
+		slash = 0
+	}
+
+	dot := strings.Index(function[slash:], ".")
+	if dot < 0 {
+		return function
+	}
+
+	return function[:slash+dot]
+}
+
+// matchPattern reports whether name matches the pattern. Patterns use the
+// syntax of path.Match and a trailing "/..." matches the path and any path
+// below it (e.g. "net/..." matches "net" and "net/http").
+func matchPattern(pattern, name string) bool {
+	if base, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if name == base || strings.HasPrefix(name, base+"/") {
+			return true
+		}
+	}
+
+	matched, _ := path.Match(pattern, name)
+
+	return matched
+}
+
+// matchAny reports whether name matches any of the patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DropPackages returns a filter that removes the frames whose package matches
+// any of the patterns (e.g. "runtime", "testing", "net/http/...").
+func DropPackages(patterns ...string) FrameFilter {
+	return func(fs Frames) Frames {
+		filtered := make(Frames, 0, len(fs))
+		for _, f := range fs {
+			if matchAny(patterns, framePackage(f.Function)) {
+				continue
+			}
+
+			filtered = append(filtered, f)
+		}
+
+		return filtered
+	}
+}
+
+// KeepPackages returns a filter that keeps only the frames whose package
+// matches any of the patterns (e.g. "github.com/you/module/...").
+func KeepPackages(patterns ...string) FrameFilter {
+	return func(fs Frames) Frames {
+		filtered := make(Frames, 0, len(fs))
+		for _, f := range fs {
+			if !matchAny(patterns, framePackage(f.Function)) {
+				continue
+			}
+
+			filtered = append(filtered, f)
+		}
+
+		return filtered
+	}
+}
+
+// TrimAt returns a filter that removes the first frame whose function matches
+// any of the patterns (e.g. "net/http.HandlerFunc.ServeHTTP") and all of the
+// frames below it.
+func TrimAt(patterns ...string) FrameFilter {
+	return func(fs Frames) Frames {
+		for i, f := range fs {
+			if matchAny(patterns, f.Function) {
+				return fs[:i]
+			}
+		}
+
+		return fs
+	}
+}
+
+// MaxDepth returns a filter that keeps at most n frames from the top of the
+// stack.
+func MaxDepth(n int) FrameFilter {
+	return func(fs Frames) Frames {
+		if n < 0 || len(fs) <= n {
+			return fs
+		}
+
+		return fs[:n]
+	}
+}
+
+// defaultFilters is the package level setting for frame filters. These are
+// what will be used if no filters are given in the trace options.
+var defaultFilters atomic.Pointer[[]FrameFilter]
+
+// SetDefaultFilters changes the default frame filters used by calls to Trace,
+// TraceN, and TraceWithOptions. By default no filters are used. It is safe to
+// call while errors are being traced.
+func SetDefaultFilters(filters ...FrameFilter) {
+	defaultFilters.Store(&filters)
+}
+
+// getDefaultFilters returns the package level frame filters.
+func getDefaultFilters() []FrameFilter {
+	if filters := defaultFilters.Load(); filters != nil {
+		return *filters
+	}
+
+	return nil
+}
+
+// filterData applies the filters to data if it is a Filterer. Other data is
+// returned unchanged.
+func filterData(data any, filters []FrameFilter) any {
+	if len(filters) == 0 {
+		return data
+	}
+
+	if f, ok := data.(Filterer); ok {
+		return f.FilterFrames(filters...)
+	}
+
+	return data
+}
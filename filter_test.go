@@ -0,0 +1,185 @@
+package oops_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func functions(fs oops.Frames) []string {
+	names := make([]string, 0, len(fs))
+	for _, f := range fs {
+		names = append(names, f.Function)
+	}
+
+	return names
+}
+
+func TestFramesFilter(t *testing.T) {
+	fs := oops.Frames{
+		{Function: "github.com/you/app/store.(*DB).Get"},
+		{Function: "github.com/you/app/api.handler.func1"},
+		{Function: "net/http.HandlerFunc.ServeHTTP"},
+		{Function: "net/http.serverHandler.ServeHTTP"},
+		{Function: "net/http.(*conn).serve"},
+		{Function: "main.main"},
+		{Function: "runtime.goexit"},
+	}
+
+	type TC struct {
+		Name    string
+		Filters []oops.FrameFilter
+		Expect  []string
+	}
+
+	tcs := []TC{
+		{
+			Name:   "none",
+			Expect: functions(fs),
+		},
+		{
+			Name:    "DropPackages",
+			Filters: []oops.FrameFilter{oops.DropPackages("net/...", "runtime")},
+			Expect: []string{
+				"github.com/you/app/store.(*DB).Get",
+				"github.com/you/app/api.handler.func1",
+				"main.main",
+			},
+		},
+		{
+			Name:    "DropPackages glob",
+			Filters: []oops.FrameFilter{oops.DropPackages("github.com/you/app/*")},
+			Expect: []string{
+				"net/http.HandlerFunc.ServeHTTP",
+				"net/http.serverHandler.ServeHTTP",
+				"net/http.(*conn).serve",
+				"main.main",
+				"runtime.goexit",
+			},
+		},
+		{
+			Name:    "KeepPackages",
+			Filters: []oops.FrameFilter{oops.KeepPackages("github.com/you/app/...")},
+			Expect: []string{
+				"github.com/you/app/store.(*DB).Get",
+				"github.com/you/app/api.handler.func1",
+			},
+		},
+		{
+			Name:    "TrimAt",
+			Filters: []oops.FrameFilter{oops.TrimAt("net/http.HandlerFunc.ServeHTTP")},
+			Expect: []string{
+				"github.com/you/app/store.(*DB).Get",
+				"github.com/you/app/api.handler.func1",
+			},
+		},
+		{
+			Name:    "TrimAt missing",
+			Filters: []oops.FrameFilter{oops.TrimAt("main.other")},
+			Expect:  functions(fs),
+		},
+		{
+			Name:    "MaxDepth",
+			Filters: []oops.FrameFilter{oops.MaxDepth(1)},
+			Expect: []string{
+				"github.com/you/app/store.(*DB).Get",
+			},
+		},
+		{
+			Name:    "MaxDepth larger",
+			Filters: []oops.FrameFilter{oops.MaxDepth(100)},
+			Expect:  functions(fs),
+		},
+		{
+			Name:    "combined",
+			Filters: []oops.FrameFilter{oops.DropPackages("github.com/you/app/store"), oops.MaxDepth(2)},
+			Expect: []string{
+				"github.com/you/app/api.handler.func1",
+				"net/http.HandlerFunc.ServeHTTP",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Expect, functions(fs.Filter(tc.Filters...)))
+		})
+	}
+}
+
+func TestTraceFilters(t *testing.T) {
+	capturers := []struct {
+		Name     string
+		Capturer oops.Capturer
+	}{
+		{Name: "CaptureFrames", Capturer: oops.CaptureFunc[oops.Frames](oops.CaptureFrames)},
+		{Name: "CaptureLazyFrames", Capturer: oops.CaptureFunc[*oops.LazyFrames](oops.CaptureLazyFrames)},
+		{Name: "CaptureSource", Capturer: oops.CaptureSource(1)},
+	}
+
+	for _, c := range capturers {
+		t.Run(c.Name, func(t *testing.T) {
+			err := oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+				Skip:     oops.TraceSkipInternal - 1,
+				Capturer: c.Capturer,
+				Filters:  []oops.FrameFilter{oops.DropPackages("testing")},
+			})
+
+			fs := err.(*oops.TraceError).Frames()
+			require.Equal(t, []string{"github.com/calebcase/oops_test.TestTraceFilters.func1"}, functions(fs))
+		})
+	}
+
+	t.Run("default", func(t *testing.T) {
+		oops.SetDefaultFilters(oops.KeepPackages("github.com/calebcase/oops_test"))
+		defer oops.SetDefaultFilters()
+
+		fs := oops.New("bad stuff").(*oops.TraceError).Frames()
+		require.Equal(t, []string{"github.com/calebcase/oops_test.TestTraceFilters.func2"}, functions(fs))
+	})
+
+	t.Run("default concurrent", func(t *testing.T) {
+		defer oops.SetDefaultFilters()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+
+				oops.SetDefaultFilters(oops.MaxDepth(1))
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				require.Error(t, oops.New("bad stuff"))
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("shared LazyFrames", func(t *testing.T) {
+		lf := oops.CaptureLazyFrames(nil, 1)
+		capturer := oops.CaptureFunc[*oops.LazyFrames](func(error, int) *oops.LazyFrames {
+			return lf
+		})
+
+		first := oops.TraceWithOptions(errors.New("first"), oops.TraceOptions{
+			Capturer: capturer,
+			Filters:  []oops.FrameFilter{oops.MaxDepth(1)},
+		})
+		second := oops.TraceWithOptions(errors.New("second"), oops.TraceOptions{
+			Capturer: capturer,
+			Filters:  []oops.FrameFilter{oops.MaxDepth(2)},
+		})
+
+		require.Len(t, first.(*oops.TraceError).Frames(), 1)
+		require.Len(t, second.(*oops.TraceError).Frames(), 2)
+		require.Greater(t, len(lf.Frames()), 2)
+	})
+}
@@ -0,0 +1,127 @@
+package oops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// FingerprintFrames is the number of frames from the top of each trace that
+// are included in the fingerprint.
+var FingerprintFrames = 3
+
+// Fingerprint returns a hash of the structure of err. Errors created by the
+// same code have the same fingerprint even if their messages include
+// different values or the code has moved to different lines.
+//
+// The fingerprint includes the type of each error in the tree (including
+// hidden errors), the names of namespaces, the classes, and the function names
+// of the top FingerprintFrames frames of each trace. Messages are only
+// included for registered sentinels (see RegisterSentinel).
+//
+// The fingerprint is included as "fingerprint" in the JSON of the oops error
+// at the top of a tree (except for a ChainError which is marshalled as an
+// array) and in the envelope created by MarshalError.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := sha256.New()
+	fingerprint(h, err)
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// fingerprint writes the structure of err to w.
+func fingerprint(w io.Writer, err error) {
+	if re, ok := err.(*RedactError); ok && re != nil && re.Err != nil {
+		// Only the presentation of the error is changed.
+		fingerprint(w, re.Err)
+
+		return
+	}
+
+	fmt.Fprintf(w, "type %q\n", TypeName(err))
+
+	switch e := err.(type) {
+	case *TraceError:
+		fs := e.Frames()
+		if len(fs) > FingerprintFrames {
+			fs = fs[:FingerprintFrames]
+		}
+
+		for _, f := range fs {
+			fmt.Fprintf(w, "frame %q\n", f.Function)
+		}
+	case *NamespaceError:
+		if e != nil {
+			fmt.Fprintf(w, "namespace %q\n", e.Name)
+		}
+	case *ClassError:
+		if e != nil {
+			fmt.Fprintf(w, "class %q %q\n", e.Class.Namespace, e.Class.Code)
+		}
+	case *ShadowError:
+		if e != nil && e.Hidden != nil {
+			fmt.Fprintf(w, "hidden {\n")
+			fingerprint(w, e.Hidden)
+			fmt.Fprintf(w, "}\n")
+		}
+	case *PanicError:
+		if e != nil {
+			fmt.Fprintf(w, "value %q\n", fmt.Sprintf("%T", e.Value))
+		}
+	}
+
+	children := UnwrapAll(err)
+	if len(children) == 0 && isSentinel(err) {
+		fmt.Fprintf(w, "sentinel %q\n", err.Error())
+	}
+
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "{\n")
+		fingerprint(w, child)
+		fmt.Fprintf(w, "}\n")
+	}
+}
+
+// isSentinel reports whether a sentinel with the same type and message as err
+// is registered.
+func isSentinel(err error) bool {
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+
+	_, ok := sentinels[sentinelKey{
+		Type:    TypeName(err),
+		Message: err.Error(),
+	}]
+
+	return ok
+}
+
+// withFingerprint adds the fingerprint of err to the JSON object in bs. Other
+// JSON (e.g. null or an array) is returned unchanged.
+func withFingerprint(bs []byte, err error) []byte {
+	if len(bs) < 2 || bs[0] != '{' || bs[len(bs)-1] != '}' {
+		return bs
+	}
+
+	output := make([]byte, 0, len(bs)+40)
+	output = append(output, bs[:len(bs)-1]...)
+
+	if len(bs) > 2 {
+		output = append(output, ',')
+	}
+
+	output = append(output, `"fingerprint":"`...)
+	output = append(output, Fingerprint(err)...)
+	output = append(output, `"}`...)
+
+	return output
+}
@@ -0,0 +1,132 @@
+package oops_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+var FingerprintTest = oops.Namespace("fingerprint")
+
+var ErrFingerprintSentinel = errors.New("fingerprint sentinel")
+
+func init() {
+	oops.RegisterSentinel(ErrFingerprintSentinel)
+}
+
+func fingerprintNew(v int) error {
+	return oops.New("bad value: %d", v)
+}
+
+func fingerprintOther(v int) error {
+	return oops.New("bad value: %d", v)
+}
+
+func fingerprintTwice() (error, error) {
+	a := oops.New("first")
+
+	b := oops.New("second")
+
+	return a, b
+}
+
+func fingerprintTrace(err error) error {
+	return oops.Trace(err)
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		require.Equal(t, "", oops.Fingerprint(nil))
+	})
+
+	t.Run("values", func(t *testing.T) {
+		require.Equal(t, oops.Fingerprint(fingerprintNew(1)), oops.Fingerprint(fingerprintNew(2)))
+	})
+
+	t.Run("lines", func(t *testing.T) {
+		a, b := fingerprintTwice()
+		require.Equal(t, oops.Fingerprint(a), oops.Fingerprint(b))
+	})
+
+	t.Run("functions", func(t *testing.T) {
+		require.NotEqual(t, oops.Fingerprint(fingerprintNew(1)), oops.Fingerprint(fingerprintOther(1)))
+	})
+
+	t.Run("namespaces", func(t *testing.T) {
+		err := fingerprintNew(1)
+
+		require.NotEqual(t, oops.Fingerprint(err), oops.Fingerprint(FingerprintTest.Wrap(err)))
+		require.NotEqual(t, oops.Fingerprint(FingerprintTest.Wrap(err)), oops.Fingerprint(oops.Namespace("other").Wrap(err)))
+	})
+
+	t.Run("classes", func(t *testing.T) {
+		err := errors.New("bad stuff")
+
+		require.NotEqual(t,
+			oops.Fingerprint(FingerprintTest.Class(oops.CodeNotFound).Wrap(err)),
+			oops.Fingerprint(FingerprintTest.Class(oops.CodeConflict).Wrap(err)),
+		)
+	})
+
+	t.Run("sentinels", func(t *testing.T) {
+		require.Equal(t,
+			oops.Fingerprint(fingerprintTrace(errors.New("a"))),
+			oops.Fingerprint(fingerprintTrace(errors.New("b"))),
+		)
+		require.NotEqual(t,
+			oops.Fingerprint(fingerprintTrace(errors.New("fingerprint sentinel"))),
+			oops.Fingerprint(fingerprintTrace(errors.New("other"))),
+		)
+	})
+
+	t.Run("hidden", func(t *testing.T) {
+		require.NotEqual(t,
+			oops.Fingerprint(oops.Shadow(errors.New("a"), ErrFingerprintSentinel)),
+			oops.Fingerprint(oops.Shadow(fingerprintNew(1), ErrFingerprintSentinel)),
+		)
+	})
+
+	t.Run("MarshalError", func(t *testing.T) {
+		orig := FingerprintTest.Chain(fingerprintNew(1), ErrFingerprintSentinel)
+
+		bs, err := oops.MarshalError(orig)
+		require.NoError(t, err)
+
+		var output struct {
+			Fingerprint string `json:"fingerprint"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &output))
+		require.Equal(t, oops.Fingerprint(orig), output.Fingerprint)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+		require.Equal(t, oops.Fingerprint(orig), oops.Fingerprint(derr))
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		orig := FingerprintTest.Trace(oops.With(fingerprintNew(1), "key", "value"))
+
+		bs, err := json.Marshal(orig)
+		require.NoError(t, err)
+		t.Log(string(bs))
+
+		var output struct {
+			Fingerprint string `json:"fingerprint"`
+			Err         struct {
+				Fingerprint string `json:"fingerprint"`
+			} `json:"err"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &output))
+		require.Equal(t, oops.Fingerprint(orig), output.Fingerprint)
+
+		// Only the top of the tree has the fingerprint.
+		require.Empty(t, output.Err.Fingerprint)
+
+		bs, err = json.Marshal(oops.New("x"))
+		require.NoError(t, err)
+		require.Contains(t, string(bs), `"fingerprint":`)
+	})
+}
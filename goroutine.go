@@ -0,0 +1,178 @@
+package oops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GoroutineFrames are frames with the identity of the goroutine that captured
+// them.
+type GoroutineFrames struct {
+	Frames Frames `json:"frames"`
+
+	// Goroutine is the ID of the goroutine.
+	Goroutine int64 `json:"goroutine"`
+
+	// Labels are the pprof labels of the context given to the trace.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// TraceFrames implements Framer.
+func (gf GoroutineFrames) TraceFrames() Frames {
+	return gf.Frames
+}
+
+// FilterFrames implements Filterer.
+func (gf GoroutineFrames) FilterFrames(filters ...FrameFilter) any {
+	gf.Frames = gf.Frames.Filter(filters...)
+
+	return gf
+}
+
+// MarshalTrace implements TraceMarshaler. The frames are the data and the
+// goroutine ID and labels are added to the TraceError.
+func (gf GoroutineFrames) MarshalTrace() (data any, extra Fields) {
+	extra = Fields{
+		{Key: "goroutine", Value: gf.Goroutine},
+	}
+
+	if len(gf.Labels) > 0 {
+		extra = append(extra, Field{Key: "labels", Value: gf.Labels})
+	}
+
+	return gf.Frames, extra
+}
+
+// formatFrames implements framesFormatter. The goroutine ID and labels follow
+// the frames (and the marker if frames were elided).
+func (gf GoroutineFrames) formatFrames(format string, ref Frames, label string) []string {
+	output := []string{}
+	if len(gf.Frames) > 0 {
+		output = formatData(format, gf.Frames, ref, label)
+	}
+
+	return append(output, gf.goroutineLines()...)
+}
+
+// decodeGoroutineFrames implements TraceDecoder for GoroutineFrames.
+func decodeGoroutineFrames(fs Frames, extra map[string]json.RawMessage) (any, bool) {
+	gbs, ok := extra["goroutine"]
+	if !ok {
+		return nil, false
+	}
+
+	gf := GoroutineFrames{
+		Frames: fs,
+	}
+
+	if err := json.Unmarshal(gbs, &gf.Goroutine); err != nil {
+		return nil, false
+	}
+
+	if lbs, ok := extra["labels"]; ok {
+		if err := json.Unmarshal(lbs, &gf.Labels); err != nil {
+			return nil, false
+		}
+	}
+
+	return gf, true
+}
+
+// String returns the frames formatted by Frames.String followed by the
+// goroutine ID and labels.
+func (gf GoroutineFrames) String() string {
+	ls := gf.goroutineLines()
+	if len(gf.Frames) > 0 {
+		ls = append([]string{gf.Frames.String()}, ls...)
+	}
+
+	return strings.Join(ls, "\n")
+}
+
+// goroutineLines returns the goroutine ID and the labels in key order.
+func (gf GoroutineFrames) goroutineLines() []string {
+	ls := []string{
+		fmt.Sprintf("goroutine: %d", gf.Goroutine),
+	}
+
+	if len(gf.Labels) == 0 {
+		return ls
+	}
+
+	keys := make([]string, 0, len(gf.Labels))
+	for k := range gf.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ls = append(ls, "labels:")
+	for _, k := range keys {
+		ls = append(ls, fmt.Sprintf("    %s: %s", k, gf.Labels[k]))
+	}
+
+	return ls
+}
+
+// goroutineCapturer captures the stack as GoroutineFrames.
+type goroutineCapturer struct{}
+
+// CaptureGoroutine returns a ContextCapturer that captures the stack as
+// GoroutineFrames. The pprof labels are read from the context given to the
+// trace (e.g. by TraceCtx) when it is captured. Without a context only the
+// goroutine ID is recorded.
+//
+//	oops.SetDefaultCapturer(oops.CaptureGoroutine())
+func CaptureGoroutine() ContextCapturer {
+	return goroutineCapturer{}
+}
+
+// Capture implements Capturer.
+func (gc goroutineCapturer) Capture(err error, skip int) any {
+	return GoroutineFrames{
+		Frames:    CaptureFrames(err, skip),
+		Goroutine: goroutineID(),
+	}
+}
+
+// CaptureContext implements ContextCapturer.
+func (gc goroutineCapturer) CaptureContext(ctx context.Context, err error, skip int) any {
+	gf := GoroutineFrames{
+		Frames:    CaptureFrames(err, skip),
+		Goroutine: goroutineID(),
+	}
+
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if gf.Labels == nil {
+			gf.Labels = map[string]string{}
+		}
+
+		gf.Labels[key] = value
+
+		return true
+	})
+
+	return gf
+}
+
+// goroutineID returns the ID of the current goroutine. It is parsed from the
+// header of the goroutine's stack (e.g. "goroutine 12 [running]:").
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+
+	id, _ := strconv.ParseInt(string(buf), 10, 64)
+
+	return id
+}
@@ -0,0 +1,83 @@
+package oops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func goroutineTrace(ctx context.Context) error {
+	return oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: oops.CaptureGoroutine(),
+		Context:  ctx,
+	})
+}
+
+func TestCaptureGoroutine(t *testing.T) {
+	var err error
+	pprof.Do(context.Background(), pprof.Labels("task", "abc", "pool", "workers"), func(ctx context.Context) {
+		err = goroutineTrace(ctx)
+	})
+
+	te := err.(*oops.TraceError)
+	gf, ok := te.Data.(oops.GoroutineFrames)
+	require.True(t, ok)
+	require.Equal(t, "github.com/calebcase/oops_test.goroutineTrace", gf.Frames[0].Function)
+	require.Positive(t, gf.Goroutine)
+	require.Equal(t, map[string]string{"task": "abc", "pool": "workers"}, gf.Labels)
+
+	t.Run("goroutines", func(t *testing.T) {
+		ch := make(chan error)
+		go func() {
+			ch <- goroutineTrace(context.Background())
+		}()
+
+		other := (<-ch).(*oops.TraceError).Data.(oops.GoroutineFrames)
+		require.NotEqual(t, gf.Goroutine, other.Goroutine)
+		require.Nil(t, other.Labels)
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		output := fmt.Sprintf("%+v", err)
+		require.True(t, strings.HasSuffix(output, strings.Join([]string{
+			fmt.Sprintf("··goroutine: %d", gf.Goroutine),
+			"··labels:",
+			"··    pool: workers",
+			"··    task: abc",
+		}, "\n")), output)
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+
+		var output struct {
+			Data      []map[string]any  `json:"data"`
+			Goroutine int64             `json:"goroutine"`
+			Labels    map[string]string `json:"labels"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &output))
+		require.Len(t, output.Data, len(gf.Frames))
+		require.Equal(t, gf.Goroutine, output.Goroutine)
+		require.Equal(t, gf.Labels, output.Labels)
+
+		mbs, jerr := oops.MarshalError(err)
+		require.NoError(t, jerr)
+
+		derr, jerr := oops.UnmarshalError(mbs)
+		require.NoError(t, jerr)
+
+		dgf, ok := derr.(*oops.TraceError).Data.(oops.GoroutineFrames)
+		require.True(t, ok)
+		require.Equal(t, gf.Goroutine, dgf.Goroutine)
+		require.Equal(t, gf.Labels, dgf.Labels)
+	})
+}
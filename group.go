@@ -0,0 +1,112 @@
+package oops
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Group runs functions concurrently and chains their errors together. The
+// zero value is ready to use, does not limit concurrency, and does not cancel
+// on failure.
+type Group struct {
+	cancel func(error)
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup returns a new Group and a context derived from ctx. The context is
+// canceled the first time a function passed to Go returns an error (or
+// panics) or the first time Wait returns, whichever occurs first. The cause of
+// the cancellation is the first error.
+func NewGroup(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	return &Group{
+		cancel: cancel,
+	}, ctx
+}
+
+// SetLimit limits the number of functions running concurrently to n. A
+// negative n removes the limit. It must not be called while functions are
+// running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+
+		return
+	}
+
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("oops: modify limit while %v functions are still running", len(g.sem)))
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls f in a new goroutine. If the limit has been reached, then Go blocks
+// until f can be started. Panics in f are recovered into traced errors.
+func (g *Group) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.mu.Lock()
+	index := len(g.errs)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+
+		err := g.run(f)
+		if err == nil {
+			return
+		}
+
+		g.mu.Lock()
+		g.errs[index] = err
+		g.mu.Unlock()
+
+		if g.cancel != nil {
+			g.cancel(err)
+		}
+	}()
+}
+
+// run calls f and recovers any panics.
+func (g *Group) run(f func() error) (err error) {
+	defer Recover(&err)
+
+	return f()
+}
+
+// done releases the resources held for a call to f.
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+
+	g.wg.Done()
+}
+
+// Wait blocks until all the functions have returned and then returns their
+// errors combined with Chain. The errors are in the order the functions were
+// passed to Go.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return Chain(g.errs...)
+}
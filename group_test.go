@@ -0,0 +1,116 @@
+package oops_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		g := &oops.Group{}
+		g.Go(func() error { return nil })
+
+		require.NoError(t, g.Wait())
+	})
+
+	t.Run("order", func(t *testing.T) {
+		g := &oops.Group{}
+
+		errs := make([]error, 5)
+		for i := range errs {
+			i := i
+			errs[i] = fmt.Errorf("%d", i)
+
+			g.Go(func() error {
+				// Finish in reverse order.
+				time.Sleep(time.Duration(len(errs)-i) * time.Millisecond)
+
+				if i == 2 {
+					return nil
+				}
+
+				return errs[i]
+			})
+		}
+
+		err := g.Wait()
+		t.Logf("%+v", err)
+		require.Equal(t, oops.ChainError{errs[0], errs[1], errs[3], errs[4]}, err)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		g := &oops.Group{}
+		g.Go(func() error {
+			panic("bad stuff")
+		})
+
+		err := g.Wait()
+		t.Logf("%+v", err)
+
+		pe := &oops.PanicError{}
+		require.ErrorAs(t, err, &pe)
+		require.Equal(t, "bad stuff", pe.Value)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		g := &oops.Group{}
+		g.SetLimit(2)
+
+		var running, peak int32
+		for i := 0; i < 10; i++ {
+			g.Go(func() error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Millisecond)
+
+				return nil
+			})
+		}
+
+		require.NoError(t, g.Wait())
+		require.LessOrEqual(t, peak, int32(2))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		g, ctx := oops.NewGroup(context.Background())
+
+		first := errors.New("first")
+		g.Go(func() error {
+			return first
+		})
+
+		g.Go(func() error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		})
+
+		err := g.Wait()
+		require.ErrorIs(t, err, first)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, first, context.Cause(ctx))
+	})
+
+	t.Run("wait cancels", func(t *testing.T) {
+		g, ctx := oops.NewGroup(context.Background())
+		g.Go(func() error { return nil })
+
+		require.NoError(t, g.Wait())
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
@@ -0,0 +1,287 @@
+// Package grpcerr translates oops errors to and from gRPC statuses.
+//
+// Server interceptors convert the errors returned by handlers into statuses.
+// Only the public side of an error reaches the client: the status message is
+// taken from the Err of the first oops.ShadowError in the tree and is
+// otherwise the name of the status code. The class of the error (see
+// oops.Class) is sent as an errdetails.ErrorInfo with the class's namespace
+// as the domain and its code as the reason.
+//
+// Client interceptors convert statuses back into oops errors with the same
+// class so that errors.Is works against the classes defined on the server.
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/calebcase/oops"
+)
+
+// Codes maps oops codes to gRPC codes.
+var Codes = map[oops.Code]codes.Code{
+	oops.CodeCanceled:           codes.Canceled,
+	oops.CodeInvalidArgument:    codes.InvalidArgument,
+	oops.CodeDeadlineExceeded:   codes.DeadlineExceeded,
+	oops.CodeNotFound:           codes.NotFound,
+	oops.CodeAlreadyExists:      codes.AlreadyExists,
+	oops.CodeConflict:           codes.Aborted,
+	oops.CodePermissionDenied:   codes.PermissionDenied,
+	oops.CodeUnauthenticated:    codes.Unauthenticated,
+	oops.CodeResourceExhausted:  codes.ResourceExhausted,
+	oops.CodeFailedPrecondition: codes.FailedPrecondition,
+	oops.CodeUnimplemented:      codes.Unimplemented,
+	oops.CodeInternal:           codes.Internal,
+	oops.CodeUnavailable:        codes.Unavailable,
+}
+
+// Reasons maps gRPC codes to the oops codes used for the classes of errors
+// created by FromStatus. Codes that are not in the map use their lowercased
+// name (e.g. "data_loss").
+var Reasons = map[codes.Code]oops.Code{
+	codes.Canceled:           oops.CodeCanceled,
+	codes.InvalidArgument:    oops.CodeInvalidArgument,
+	codes.DeadlineExceeded:   oops.CodeDeadlineExceeded,
+	codes.NotFound:           oops.CodeNotFound,
+	codes.AlreadyExists:      oops.CodeAlreadyExists,
+	codes.Aborted:            oops.CodeConflict,
+	codes.PermissionDenied:   oops.CodePermissionDenied,
+	codes.Unauthenticated:    oops.CodeUnauthenticated,
+	codes.ResourceExhausted:  oops.CodeResourceExhausted,
+	codes.FailedPrecondition: oops.CodeFailedPrecondition,
+	codes.Unimplemented:      oops.CodeUnimplemented,
+	codes.Internal:           oops.CodeInternal,
+	codes.Unavailable:        oops.CodeUnavailable,
+}
+
+// Code returns the gRPC code for err. The code of err's class is looked up in
+// Codes. Errors without a known code use the code of a status in their tree
+// or are mapped from context.Canceled and context.DeadlineExceeded and are
+// otherwise unknown.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	if c, ok := Codes[oops.CodeOf(err)]; ok {
+		return c
+	}
+
+	var gs interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &gs) && gs.GRPCStatus() != nil {
+		return gs.GRPCStatus().Code()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+
+	return codes.Unknown
+}
+
+// Status returns the status for err. If err has a class or a namespace, then
+// the status has an ErrorInfo detail with the namespace as the domain and the
+// code as the reason. If there is already a status in err's tree (e.g. a
+// traced error from another client), then it is returned unchanged.
+func Status(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var gs interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &gs) && gs.GRPCStatus() != nil {
+		return gs.GRPCStatus()
+	}
+
+	code := Code(err)
+
+	msg := code.String()
+
+	var se *oops.ShadowError
+	if errors.As(err, &se) && se.Err != nil {
+		msg = se.Err.Error()
+	}
+
+	st := status.New(code, msg)
+
+	class, ok := oops.ClassOf(err)
+	if !ok {
+		class.Code = reason(code)
+	}
+
+	if class.Namespace == "" {
+		var ne *oops.NamespaceError
+		if errors.As(err, &ne) {
+			class.Namespace = oops.Namespace(ne.Name)
+		}
+	}
+
+	if !ok && class.Namespace == "" {
+		return st
+	}
+
+	dst, derr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strings.ToUpper(string(class.Code)),
+		Domain: string(class.Namespace),
+	})
+	if derr != nil {
+		return st
+	}
+
+	return dst
+}
+
+// reason returns the oops code for the gRPC code.
+func reason(code codes.Code) oops.Code {
+	if oc, ok := Reasons[code]; ok {
+		return oc
+	}
+
+	return oops.Code(strings.ToLower(code.String()))
+}
+
+// StatusError is an error created from a status received by a client.
+type StatusError struct {
+	Status *status.Status
+}
+
+// Error implements error.
+func (se *StatusError) Error() string {
+	if se == nil {
+		return ""
+	}
+
+	return se.Status.Message()
+}
+
+// GRPCStatus returns the status. It allows the status to be retrieved with
+// status.FromError.
+func (se *StatusError) GRPCStatus() *status.Status {
+	if se == nil {
+		return nil
+	}
+
+	return se.Status
+}
+
+// FromStatus returns an oops error for st. If st has an ErrorInfo detail, then
+// the error has the class with the domain as the namespace and the reason as
+// the code. Otherwise the class has the oops code for st's code (or its
+// lowercased name) and no namespace. The status itself is available with
+// status.FromError.
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	var err error = &StatusError{
+		Status: st,
+	}
+
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+
+		ns := oops.Namespace(info.Domain)
+		if ns != "" && strings.HasPrefix(st.Message(), info.Domain+": ") {
+			// Avoid repeating the namespace in the message.
+			p := st.Proto()
+			p.Message = strings.TrimPrefix(p.Message, info.Domain+": ")
+
+			err = &StatusError{
+				Status: status.FromProto(p),
+			}
+		}
+
+		return ns.Class(oops.Code(strings.ToLower(info.Reason))).Wrap(err)
+	}
+
+	return oops.Class{Code: reason(st.Code())}.Wrap(err)
+}
+
+// toStatusError converts err to an error with a status.
+func toStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return Status(err).Err()
+}
+
+// fromStatusError converts err to an oops error if it has a status.
+func fromStatusError(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return FromStatus(st)
+}
+
+// UnaryServerInterceptor returns an interceptor that converts the errors from
+// handlers into statuses.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+
+		return resp, toStatusError(err)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that converts the errors
+// from handlers into statuses.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return toStatusError(handler(srv, ss))
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor that converts statuses into
+// oops errors.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return fromStatusError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that converts statuses into
+// oops errors.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, fromStatusError(err)
+		}
+
+		return &clientStream{cs}, nil
+	}
+}
+
+// clientStream converts the errors from the stream into oops errors.
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (cs *clientStream) SendMsg(m any) error {
+	return fromStatusError(cs.ClientStream.SendMsg(m))
+}
+
+func (cs *clientStream) RecvMsg(m any) error {
+	return fromStatusError(cs.ClientStream.RecvMsg(m))
+}
@@ -0,0 +1,212 @@
+package grpcerr_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/calebcase/oops"
+	"github.com/calebcase/oops/grpcerr"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	Error = oops.Namespace("grpcerr_test")
+
+	ErrNotFound = Error.Class(oops.CodeNotFound)
+)
+
+// healthServer returns the error for the requested service.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	errs map[string]error
+}
+
+func (hs *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := hs.errs[req.Service]; err != nil {
+		return nil, err
+	}
+
+	return &grpc_health_v1.HealthCheckResponse{
+		Status: grpc_health_v1.HealthCheckResponse_SERVING,
+	}, nil
+}
+
+func (hs *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	return hs.errs[req.Service]
+}
+
+func dial(t *testing.T, hs *healthServer) grpc_health_v1.HealthClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(grpcerr.StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(srv, hs)
+
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcerr.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(grpcerr.StreamClientInterceptor()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("class", func(t *testing.T) {
+		st := grpcerr.Status(oops.Shadow(
+			errors.New("secret"),
+			ErrNotFound.Wrap(errors.New("thing not found")),
+		))
+
+		require.Equal(t, codes.NotFound, st.Code())
+		require.Equal(t, "grpcerr_test: thing not found", st.Message())
+		require.Len(t, st.Details(), 1)
+
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		require.Equal(t, "grpcerr_test", info.Domain)
+		require.Equal(t, "NOT_FOUND", info.Reason)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		st := grpcerr.Status(Error.New("secret"))
+
+		require.Equal(t, codes.Unknown, st.Code())
+		require.Equal(t, "Unknown", st.Message())
+
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		require.Equal(t, "grpcerr_test", info.Domain)
+		require.Equal(t, "UNKNOWN", info.Reason)
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		st := grpcerr.Status(oops.Trace(context.DeadlineExceeded))
+
+		require.Equal(t, codes.DeadlineExceeded, st.Code())
+		require.Empty(t, st.Details())
+	})
+
+	t.Run("status", func(t *testing.T) {
+		orig := status.New(codes.Aborted, "aborted")
+		require.Equal(t, orig, grpcerr.Status(orig.Err()))
+		require.Equal(t, codes.Aborted, grpcerr.Code(oops.Trace(orig.Err())))
+	})
+
+	t.Run("wrapped status", func(t *testing.T) {
+		orig, err := status.New(codes.Aborted, "aborted").WithDetails(&errdetails.ErrorInfo{
+			Reason: "LOCKED",
+			Domain: "upstream",
+		})
+		require.NoError(t, err)
+
+		st := grpcerr.Status(oops.Trace(orig.Err()))
+		require.Equal(t, orig, st)
+		require.Equal(t, "aborted", st.Message())
+		require.Len(t, st.Details(), 1)
+	})
+
+	t.Run("reason", func(t *testing.T) {
+		for code, oc := range grpcerr.Reasons {
+			require.Equal(t, code, grpcerr.Codes[oc], oc)
+
+			class := &oops.ClassError{}
+			require.ErrorAs(t, grpcerr.FromStatus(status.New(code, "x")), &class)
+			require.Equal(t, oc, class.Class.Code)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.Nil(t, grpcerr.Status(nil))
+		require.Equal(t, codes.OK, grpcerr.Code(nil))
+		require.Nil(t, grpcerr.FromStatus(nil))
+	})
+}
+
+func TestInterceptors(t *testing.T) {
+	client := dial(t, &healthServer{
+		errs: map[string]error{
+			"shadow": oops.Shadow(
+				oops.New("db password=hunter2 rejected"),
+				ErrNotFound.New("thing not found"),
+			),
+			"plain":  errors.New("internal details"),
+			"status": status.Error(codes.Unavailable, "try later"),
+		},
+	})
+
+	ctx := context.Background()
+
+	t.Run("unary", func(t *testing.T) {
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "shadow"})
+		require.Error(t, err)
+		t.Logf("%+v", err)
+
+		require.Equal(t, "grpcerr_test: thing not found", err.Error())
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Equal(t, oops.CodeNotFound, oops.CodeOf(err))
+
+		ne := &oops.NamespaceError{}
+		require.ErrorAs(t, err, &ne)
+		require.Equal(t, "grpcerr_test", ne.Name)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Equal(t, codes.NotFound, st.Code())
+	})
+
+	t.Run("unary plain", func(t *testing.T) {
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "plain"})
+		require.Error(t, err)
+
+		require.NotContains(t, err.Error(), "internal details")
+		require.Equal(t, codes.Unknown, status.Code(err))
+	})
+
+	t.Run("unary status", func(t *testing.T) {
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "status"})
+		require.Error(t, err)
+
+		require.Equal(t, "try later", err.Error())
+		require.Equal(t, oops.CodeUnavailable, oops.CodeOf(err))
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("unary ok", func(t *testing.T) {
+		resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "shadow"})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Error(t, err)
+
+		require.Equal(t, "grpcerr_test: thing not found", err.Error())
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,115 @@
+package oops
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// helperPCs are the call sites of Helper that have been resolved.
+	helperPCs sync.Map
+
+	// helperMarked is set once Helper has marked a function.
+	helperMarked atomic.Bool
+
+	// helperFunctions are the names of the functions marked by Helper.
+	helperFunctions sync.Map
+
+	// helperPackages are the patterns of the packages set by
+	// SetHelperPackages.
+	helperPackages atomic.Pointer[[]string]
+)
+
+// Helper marks the calling function as a helper (similar to
+// testing.T.Helper). Frames of helpers at the top of a captured stack are
+// removed so the trace starts at the first caller that isn't a helper. This
+// allows wrapping Trace or New without adjusting the skip:
+//
+//	func wrap(err error) error {
+//		oops.Helper()
+//
+//		return oops.Trace(fmt.Errorf("store: %w", err))
+//	}
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+
+	if _, ok := helperPCs.Load(pcs[0]); ok {
+		return
+	}
+
+	f, _ := runtime.CallersFrames(pcs[:]).Next()
+	helperFunctions.Store(f.Function, true)
+	helperPCs.Store(pcs[0], true)
+	helperMarked.Store(true)
+}
+
+// SetHelperPackages sets the packages whose frames are treated as helpers.
+// The patterns have the same syntax as DropPackages (e.g.
+// "github.com/you/app/errs/..."). By default there are no helper packages. It
+// is safe to call while errors are being traced.
+func SetHelperPackages(patterns ...string) {
+	helperPackages.Store(&patterns)
+}
+
+// getHelperPackages returns the package level helper package patterns.
+func getHelperPackages() []string {
+	if patterns := helperPackages.Load(); patterns != nil {
+		return *patterns
+	}
+
+	return nil
+}
+
+// isHelper reports whether the frame is from a helper function or package.
+func isHelper(f runtime.Frame) bool {
+	if _, ok := helperFunctions.Load(f.Function); ok {
+		return true
+	}
+
+	patterns := getHelperPackages()
+
+	return len(patterns) > 0 && matchAny(patterns, framePackage(f.Function))
+}
+
+// helperFilters removes the helpers from captured trace data. It is shared so
+// that each trace doesn't allocate it.
+var helperFilters = []FrameFilter{trimHelpers}
+
+// trimHelpers removes the frames of helpers at the top of the stack. If every
+// frame is from a helper, then the frames are returned unchanged.
+func trimHelpers(fs Frames) Frames {
+	for i, f := range fs {
+		if !isHelper(f) {
+			return fs[i:]
+		}
+	}
+
+	return fs
+}
+
+// callSite returns the program counter of the first caller that isn't a
+// helper. The skip is the same as for runtime.Callers called by the caller of
+// callSite. If every caller is a helper, then the first caller is returned.
+func callSite(skip int) uintptr {
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pcs) == 0 {
+		return 0
+	}
+
+	if !helperMarked.Load() && len(getHelperPackages()) == 0 {
+		return pcs[0]
+	}
+
+	for _, pc := range callers(skip + 2) {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !isHelper(f) {
+			return pc
+		}
+	}
+
+	return pcs[0]
+}
@@ -0,0 +1,160 @@
+package oops_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func helperTrace(err error) error {
+	oops.Helper()
+
+	return oops.Trace(fmt.Errorf("helper: %w", err))
+}
+
+func helperWrap(err error) error {
+	oops.Helper()
+
+	return helperTrace(err)
+}
+
+func helperNested(err error) error {
+	oops.Helper()
+
+	return helperWrap(err)
+}
+
+func helperNotMarked(err error) error {
+	return helperNested(err)
+}
+
+func helperLazy(err error) error {
+	oops.Helper()
+
+	return oops.TraceWithOptions(err, oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: oops.CaptureFunc[*oops.LazyFrames](oops.CaptureLazyFrames),
+		Filters:  []oops.FrameFilter{oops.MaxDepth(1)},
+	})
+}
+
+func helperPanic() {
+	oops.Helper()
+
+	panic("bad stuff")
+}
+
+func helperSampled(c oops.Capturer) error {
+	oops.Helper()
+
+	return oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: c,
+	})
+}
+
+func recoverHelper() (err error) {
+	defer oops.Recover(&err)
+
+	helperPanic()
+
+	return nil
+}
+
+func topFunction(t *testing.T, err error) string {
+	t.Helper()
+
+	te := &oops.TraceError{}
+	require.ErrorAs(t, err, &te)
+
+	fs := te.Frames()
+	require.NotEmpty(t, fs)
+
+	return fs[0].Function
+}
+
+func TestHelper(t *testing.T) {
+	base := errors.New("bad stuff")
+
+	t.Run("single", func(t *testing.T) {
+		require.Equal(t, "github.com/calebcase/oops_test.TestHelper.func1", topFunction(t, helperTrace(base)))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		require.Equal(t, "github.com/calebcase/oops_test.TestHelper.func2", topFunction(t, helperNested(base)))
+
+		for i := 0; i < 3; i++ {
+			require.Equal(t, "github.com/calebcase/oops_test.TestHelper.func2", topFunction(t, helperWrap(base)))
+		}
+	})
+
+	t.Run("not marked", func(t *testing.T) {
+		require.Equal(t, "github.com/calebcase/oops_test.helperNotMarked", topFunction(t, helperNotMarked(base)))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		err := helperLazy(base)
+
+		te := err.(*oops.TraceError)
+		require.Equal(t, []string{"github.com/calebcase/oops_test.TestHelper.func4"}, functions(te.Frames()))
+	})
+
+	t.Run("Recover", func(t *testing.T) {
+		require.Equal(t, "github.com/calebcase/oops_test.recoverHelper", topFunction(t, recoverHelper()))
+	})
+
+	t.Run("CaptureSampled", func(t *testing.T) {
+		pcs := []uintptr{}
+		c := oops.CaptureSampled(nil, oops.SamplerFunc(func(pc uintptr) bool {
+			pcs = append(pcs, pc)
+
+			return false
+		}))
+
+		errs := []error{
+			helperSampled(c),
+			helperSampled(c),
+		}
+
+		require.Len(t, pcs, 2)
+		require.NotEqual(t, pcs[0], pcs[1])
+
+		for _, err := range errs {
+			output := fmt.Sprintf("%+v", err)
+			require.Contains(t, output, "trace sampled out at github.com/calebcase/oops_test.TestHelper.func6\n")
+			require.NotContains(t, output, "helperSampled")
+		}
+	})
+
+	t.Run("SetHelperPackages", func(t *testing.T) {
+		oops.SetHelperPackages("github.com/calebcase/oops_test")
+		defer oops.SetHelperPackages()
+
+		require.Equal(t, "testing.tRunner", topFunction(t, oops.New("bad stuff")))
+	})
+	t.Run("SetHelperPackages concurrent", func(t *testing.T) {
+		defer oops.SetHelperPackages()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+
+				oops.SetHelperPackages("github.com/calebcase/oops_test")
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				require.Error(t, oops.New("bad stuff"))
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,194 @@
+// Package httperr writes oops errors as HTTP problem details (RFC 7807)
+// responses.
+//
+// Only the public side of an error reaches the client: the detail of the
+// problem is taken from the Err of the first oops.ShadowError in the tree and
+// is omitted otherwise. The full error, including hidden errors and their
+// traces, is written to the server log.
+package httperr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/calebcase/oops"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest is the non-standard status used when the client
+// canceled the request.
+const StatusClientClosedRequest = 499
+
+// Statuses maps error codes to HTTP status codes.
+var Statuses = map[oops.Code]int{
+	oops.CodeCanceled:           StatusClientClosedRequest,
+	oops.CodeInvalidArgument:    http.StatusBadRequest,
+	oops.CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+	oops.CodeNotFound:           http.StatusNotFound,
+	oops.CodeAlreadyExists:      http.StatusConflict,
+	oops.CodeConflict:           http.StatusConflict,
+	oops.CodePermissionDenied:   http.StatusForbidden,
+	oops.CodeUnauthenticated:    http.StatusUnauthorized,
+	oops.CodeResourceExhausted:  http.StatusTooManyRequests,
+	oops.CodeFailedPrecondition: http.StatusPreconditionFailed,
+	oops.CodeUnimplemented:      http.StatusNotImplemented,
+	oops.CodeInternal:           http.StatusInternalServerError,
+	oops.CodeUnavailable:        http.StatusServiceUnavailable,
+}
+
+// Status returns the HTTP status code for err. The code of err's class is
+// looked up in Statuses. Errors without a known code are mapped from
+// context.Canceled and context.DeadlineExceeded or are otherwise internal
+// server errors.
+func Status(err error) int {
+	if status, ok := Statuses[oops.CodeOf(err)]; ok {
+		return status
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Code is the code of the error's class (an extension member).
+	Code oops.Code `json:"code,omitempty"`
+}
+
+// NewProblem returns the problem for err. The detail is the public error of
+// the first ShadowError in err's tree.
+func NewProblem(r *http.Request, err error) *Problem {
+	status := Status(err)
+
+	p := &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   oops.CodeOf(err),
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(http.StatusInternalServerError)
+	}
+
+	var se *oops.ShadowError
+	if errors.As(err, &se) && se.Err != nil {
+		p.Detail = se.Err.Error()
+	}
+
+	if r != nil && r.URL != nil {
+		p.Instance = r.URL.Path
+	}
+
+	return p
+}
+
+// Writer writes errors as problem details responses and logs them.
+type Writer struct {
+	// Logger receives the full error. If nil, then slog.Default is used.
+	Logger *slog.Logger
+}
+
+// Default is the writer used by the package level functions.
+var Default = &Writer{}
+
+// Write logs err and writes the problem for err to rw.
+func (w *Writer) Write(rw http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(r, err)
+
+	w.log(r, p, err)
+
+	bs, merr := json.Marshal(p)
+	if merr != nil {
+		http.Error(rw, p.Title, p.Status)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", ContentType)
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(p.Status)
+	rw.Write(bs)
+}
+
+// log writes the full error to the logger.
+func (w *Writer) log(r *http.Request, p *Problem, err error) {
+	logger := w.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	level := slog.LevelInfo
+	if p.Status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+
+	ctx := context.Background()
+	attrs := []slog.Attr{
+		slog.Int("status", p.Status),
+	}
+
+	if r != nil {
+		ctx = r.Context()
+		attrs = append(attrs,
+			slog.String("method", r.Method),
+			slog.String("path", p.Instance),
+		)
+	}
+
+	attrs = append(attrs, slog.Attr{
+		Key:   "err",
+		Value: oops.SlogValue(err, oops.SlogOptions{Hidden: true}),
+	})
+
+	logger.LogAttrs(ctx, level, "http request failed", attrs...)
+}
+
+// Recover returns middleware that recovers panics in next and writes them as
+// problem details responses. Panics with http.ErrAbortHandler are not
+// recovered.
+func (w *Writer) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var err error
+		defer func() {
+			if err == nil {
+				return
+			}
+
+			if errors.Is(err, http.ErrAbortHandler) {
+				panic(http.ErrAbortHandler)
+			}
+
+			w.Write(rw, r, err)
+		}()
+		defer oops.Recover(&err)
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// Write logs err and writes the problem for err to rw using Default.
+func Write(rw http.ResponseWriter, r *http.Request, err error) {
+	Default.Write(rw, r, err)
+}
+
+// Recover returns middleware that recovers panics in next using Default.
+func Recover(next http.Handler) http.Handler {
+	return Default.Recover(next)
+}
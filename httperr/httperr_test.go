@@ -0,0 +1,129 @@
+package httperr_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/calebcase/oops/httperr"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	Error = oops.Namespace("httperr_test")
+
+	ErrNotFound = Error.Class(oops.CodeNotFound)
+)
+
+func serve(t *testing.T, h http.Handler) (*httptest.ResponseRecorder, *httperr.Problem, string) {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/things/1", nil))
+
+	p := &httperr.Problem{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), p))
+
+	return rr, p, rr.Body.String()
+}
+
+func TestStatus(t *testing.T) {
+	require.Equal(t, http.StatusNotFound, httperr.Status(ErrNotFound.New("missing")))
+	require.Equal(t, http.StatusInternalServerError, httperr.Status(errors.New("plain")))
+	require.Equal(t, httperr.StatusClientClosedRequest, httperr.Status(oops.Trace(context.Canceled)))
+	require.Equal(t, http.StatusGatewayTimeout, httperr.Status(context.DeadlineExceeded))
+	require.Equal(t, http.StatusInternalServerError, httperr.Status(oops.Class{Code: "custom"}.Wrap(errors.New("x"))))
+}
+
+func TestWriter(t *testing.T) {
+	logs := &bytes.Buffer{}
+	w := &httperr.Writer{
+		Logger: slog.New(slog.NewJSONHandler(logs, nil)),
+	}
+
+	t.Run("shadow", func(t *testing.T) {
+		logs.Reset()
+
+		h := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			err := oops.Shadow(
+				oops.New("db password=hunter2 rejected"),
+				ErrNotFound.Wrap(errors.New("thing not found")),
+			)
+
+			w.Write(rw, r, err)
+		})
+
+		rr, p, body := serve(t, h)
+		t.Log(body)
+		t.Log(logs.String())
+
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Equal(t, httperr.ContentType, rr.Header().Get("Content-Type"))
+		require.Equal(t, &httperr.Problem{
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "httperr_test: thing not found",
+			Instance: "/things/1",
+			Code:     oops.CodeNotFound,
+		}, p)
+
+		require.NotContains(t, body, "hunter2")
+		require.Contains(t, logs.String(), "hunter2")
+		require.Contains(t, logs.String(), "TestWriter")
+	})
+
+	t.Run("unshadowed", func(t *testing.T) {
+		h := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			w.Write(rw, r, oops.New("internal details"))
+		})
+
+		rr, p, body := serve(t, h)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Equal(t, "", p.Detail)
+		require.NotContains(t, body, "internal details")
+	})
+
+	t.Run("Recover", func(t *testing.T) {
+		logs.Reset()
+
+		h := w.Recover(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			panic(fmt.Sprintf("bad stuff at %s", r.URL.Path))
+		}))
+
+		rr, p, body := serve(t, h)
+		t.Log(logs.String())
+
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+		require.Equal(t, "Internal Server Error", p.Title)
+		require.NotContains(t, body, "bad stuff")
+		require.Contains(t, logs.String(), "panic: bad stuff at /things/1")
+		require.Contains(t, logs.String(), "TestWriter")
+	})
+
+	t.Run("Recover abort", func(t *testing.T) {
+		h := w.Recover(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+
+	t.Run("Recover none", func(t *testing.T) {
+		h := w.Recover(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
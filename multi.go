@@ -0,0 +1,206 @@
+package oops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/calebcase/oops/lines"
+)
+
+// NamedCapturer is a Capturer with the name of its section in MultiData.
+type NamedCapturer struct {
+	Name     string
+	Capturer Capturer
+}
+
+// MultiCapturer runs each capturer in order and stores their captures as
+// MultiData.
+//
+//	oops.MultiCapturer{
+//		{Name: "frames", Capturer: oops.CaptureFunc[oops.Frames](oops.CaptureFrames)},
+//		{Name: "time", Capturer: oops.CaptureFunc[time.Time](oops.CaptureTime)},
+//	}
+type MultiCapturer []NamedCapturer
+
+// Capture implements Capturer.
+func (mc MultiCapturer) Capture(err error, skip int) any {
+	return mc.capture(nil, err, skip+1)
+}
+
+// CaptureContext implements ContextCapturer. The context is given to the
+// capturers that are ContextCapturers.
+func (mc MultiCapturer) CaptureContext(ctx context.Context, err error, skip int) any {
+	return mc.capture(ctx, err, skip+1)
+}
+
+// capture runs each capturer with ctx.
+func (mc MultiCapturer) capture(ctx context.Context, err error, skip int) MultiData {
+	md := make(MultiData, 0, len(mc))
+
+	for _, nc := range mc {
+		var data any
+		if cc, ok := nc.Capturer.(ContextCapturer); ok && ctx != nil {
+			data = cc.CaptureContext(ctx, err, skip+1)
+		} else {
+			data = nc.Capturer.Capture(err, skip+1)
+		}
+
+		md = append(md, Section{
+			Name: nc.Name,
+			Data: data,
+		})
+	}
+
+	return md
+}
+
+// CaptureTime returns the current time.
+func CaptureTime(_ error, _ int) time.Time {
+	return time.Now()
+}
+
+// Section is the named capture of a NamedCapturer.
+type Section struct {
+	Name string
+	Data any
+}
+
+// MultiData is the trace data captured by MultiCapturer. The sections are in
+// the order of the capturers.
+type MultiData []Section
+
+// Get returns the data of the section with the given name.
+func (md MultiData) Get(name string) (any, bool) {
+	for _, s := range md {
+		if s.Name == name {
+			return s.Data, true
+		}
+	}
+
+	return nil, false
+}
+
+// TraceFrames implements Framer. The frames of the first section that has
+// frames are returned.
+func (md MultiData) TraceFrames() Frames {
+	for _, s := range md {
+		if fs := framesOf(s.Data); fs != nil {
+			return fs
+		}
+	}
+
+	return nil
+}
+
+// FilterFrames implements Filterer. The filters are applied to each section.
+func (md MultiData) FilterFrames(filters ...FrameFilter) any {
+	output := make(MultiData, 0, len(md))
+	for _, s := range md {
+		output = append(output, Section{
+			Name: s.Name,
+			Data: filterData(s.Data, filters),
+		})
+	}
+
+	return output
+}
+
+// formatFrames implements framesFormatter. Each section is formatted under a
+// heading with its name and the frames of the sections are elided relative to
+// ref.
+func (md MultiData) formatFrames(format string, ref Frames, label string) []string {
+	output := []string{}
+
+	for _, s := range md {
+		output = append(output, s.Name+":")
+		output = append(output, lines.Indent(formatData(format, s.Data, ref, label), "    ", 0)...)
+	}
+
+	return output
+}
+
+// Format implements fmt.Format. Each section is formatted under a heading with
+// its name.
+func (md MultiData) Format(f fmt.State, verb rune) {
+	f.Write([]byte(strings.Join(md.formatFrames(fmt.FormatString(f, verb), nil, ""), "\n")))
+}
+
+// String returns the sections formatted by Format.
+func (md MultiData) String() string {
+	return fmt.Sprintf("%v", md)
+}
+
+// MarshalJSON implements json.Marshaler. The sections are marshalled as an
+// object with the names as keys in order.
+func (md MultiData) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	buf.WriteByte('{')
+	for i, s := range md {
+		kbs, err := json.Marshal(s.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		vbs, err := json.Marshal(s.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.Write(kbs)
+		buf.WriteByte(':')
+		buf.Write(vbs)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// LogValue implements slog.LogValuer.
+func (md MultiData) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(md))
+	for _, s := range md {
+		attrs = append(attrs, slog.Any(s.Name, s.Data))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// SectionOf returns the data of the named section from the first TraceError
+// in err's tree that captured MultiData with a section of that name and type.
+func SectionOf[T any](err error, name string) (data T, ok bool) {
+	Walk(err, func(node Node, _ int, _ []int) WalkAction {
+		te, isTrace := node.Err.(*TraceError)
+		if !isTrace || te == nil {
+			return WalkContinue
+		}
+
+		md, isMulti := te.Data.(MultiData)
+		if !isMulti {
+			return WalkContinue
+		}
+
+		v, found := md.Get(name)
+		if !found {
+			return WalkContinue
+		}
+
+		data, ok = v.(T)
+		if !ok {
+			return WalkContinue
+		}
+
+		return WalkStop
+	})
+
+	return data, ok
+}
@@ -0,0 +1,126 @@
+package oops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func multiTrace(c oops.Capturer) error {
+	return oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: c,
+		Filters:  []oops.FrameFilter{oops.DropPackages("testing")},
+	})
+}
+
+func TestMultiCapturer(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	c := oops.MultiCapturer{
+		{Name: "frames", Capturer: oops.CaptureFunc[oops.Frames](oops.CaptureFrames)},
+		{Name: "goroutine", Capturer: oops.CaptureGoroutine()},
+		{Name: "time", Capturer: oops.CaptureFunc[time.Time](func(error, int) time.Time {
+			return now
+		})},
+	}
+
+	err := oops.Chain(errors.New("first"), multiTrace(c))
+
+	te := &oops.TraceError{}
+	require.ErrorAs(t, err, &te)
+
+	md, ok := te.Data.(oops.MultiData)
+	require.True(t, ok)
+	require.Len(t, md, 3)
+
+	fs := te.Frames()
+	require.Equal(t, []string{
+		"github.com/calebcase/oops_test.multiTrace",
+		"github.com/calebcase/oops_test.TestMultiCapturer",
+	}, functions(fs))
+
+	gf, ok := oops.SectionOf[oops.GoroutineFrames](err, "goroutine")
+	require.True(t, ok)
+	require.Equal(t, functions(fs), functions(gf.Frames))
+
+	ts, ok := oops.SectionOf[time.Time](err, "time")
+	require.True(t, ok)
+	require.Equal(t, now, ts)
+
+	_, ok = oops.SectionOf[string](err, "time")
+	require.False(t, ok)
+
+	_, ok = oops.SectionOf[time.Time](err, "missing")
+	require.False(t, ok)
+
+	t.Run("Format", func(t *testing.T) {
+		output := fmt.Sprintf("%+v", te)
+		t.Log(output)
+
+		require.True(t, strings.HasPrefix(output, "bad stuff\n··frames:\n··    [0] github.com/calebcase/oops_test.multiTrace\n"), output)
+		require.Contains(t, output, "\n··goroutine:\n··    [0] github.com/calebcase/oops_test.multiTrace\n")
+		require.Contains(t, output, fmt.Sprintf("\n··    goroutine: %d\n", gf.Goroutine))
+		require.True(t, strings.HasSuffix(output, "\n··time:\n··    2024-01-02 03:04:05 +0000 UTC"), output)
+	})
+
+	t.Run("ContextCapturer", func(t *testing.T) {
+		var err error
+		pprof.Do(context.Background(), pprof.Labels("task", "abc"), func(ctx context.Context) {
+			err = oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+				Skip:     oops.TraceSkipInternal - 1,
+				Capturer: c,
+				Context:  ctx,
+			})
+		})
+
+		gf, ok := oops.SectionOf[oops.GoroutineFrames](err, "goroutine")
+		require.True(t, ok)
+		require.Equal(t, "github.com/calebcase/oops_test.TestMultiCapturer.func3.1", gf.Frames[0].Function)
+		require.Equal(t, map[string]string{"task": "abc"}, gf.Labels)
+	})
+
+	t.Run("dedup", func(t *testing.T) {
+		err := oops.TraceWithOptions(dedupInner(), oops.TraceOptions{
+			Skip:     oops.TraceSkipInternal - 1,
+			Capturer: c,
+		})
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		n := len(err.(*oops.TraceError).Frames()) - 1
+		require.Contains(t, output, "\n··frames:\n··    [0] github.com/calebcase/oops_test.TestMultiCapturer.func4\n")
+		require.Contains(t, output, fmt.Sprintf("\n··    … %d frames in common with inner trace\n··goroutine:\n", n))
+		require.Equal(t, 2, strings.Count(output, "frames in common with inner trace"))
+		require.Equal(t, 1, strings.Count(output, "testing.tRunner"))
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		bs, jerr := json.Marshal(md)
+		require.NoError(t, jerr)
+		require.True(t, strings.HasPrefix(string(bs), `{"frames":[`), string(bs))
+		require.True(t, strings.HasSuffix(string(bs), `,"time":"2024-01-02T03:04:05Z"}`), string(bs))
+
+		var output struct {
+			Frames    []map[string]any `json:"frames"`
+			Goroutine struct {
+				Frames    []map[string]any `json:"frames"`
+				Goroutine int64            `json:"goroutine"`
+			} `json:"goroutine"`
+			Time time.Time `json:"time"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &output))
+		require.Len(t, output.Frames, 2)
+		require.Equal(t, gf.Goroutine, output.Goroutine.Goroutine)
+		require.Equal(t, now, output.Time)
+	})
+}
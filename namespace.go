@@ -1,6 +1,9 @@
 package oops
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // Namespace provides a name prefix for new errors.
 type Namespace string
@@ -32,10 +35,24 @@ func (n Namespace) New(format string, a ...any) error {
 	return n.Wrap(TraceN(fmt.Errorf(format, a...), TraceSkipInternal))
 }
 
+func (n Namespace) NewCtx(ctx context.Context, format string, a ...any) error {
+	return n.Wrap(withContext(ctx, TraceWithOptions(fmt.Errorf(format, a...), TraceOptions{
+		Skip:    TraceSkipInternal,
+		Context: ctx,
+	})))
+}
+
 func (n Namespace) Trace(err error) error {
 	return n.Wrap(TraceN(err, TraceSkipInternal))
 }
 
+func (n Namespace) TraceCtx(ctx context.Context, err error) error {
+	return n.Wrap(withContext(ctx, TraceWithOptions(err, TraceOptions{
+		Skip:    TraceSkipInternal,
+		Context: ctx,
+	})))
+}
+
 func (n Namespace) TraceN(err error, skip int) error {
 	return n.Wrap(TraceN(err, skip))
 }
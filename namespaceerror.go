@@ -37,6 +37,11 @@ func (ne *NamespaceError) Unwrap() error {
 
 // Format implements fmt.Format.
 func (ne *NamespaceError) Format(f fmt.State, verb rune) {
+	ne.formatRelative(f, verb, nil, redactPolicy.Load())
+}
+
+// formatRelative implements relativeFormatter.
+func (ne *NamespaceError) formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy) {
 	if ne == nil || ne.Err == nil {
 		fmt.Fprintf(f, "<nil>")
 
@@ -55,7 +60,7 @@ func (ne *NamespaceError) Format(f fmt.State, verb rune) {
 	}
 
 	output := []string{}
-	ls := lines.Sprintf("%"+flag+string(verb), ne.Err)
+	ls := lines.Sprintf("%"+flag+string(verb), relativeTo{ne.Err, ref, policy})
 
 	output = append(output, fmt.Sprintf("%s: %s", ne.Name, ls[0]))
 
@@ -68,20 +73,27 @@ func (ne *NamespaceError) Format(f fmt.State, verb rune) {
 
 // MarshalJSON implements json.Marshaler.
 func (ne *NamespaceError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(ne)
+}
+
+// marshalJSON implements policyMarshaler.
+func (ne *NamespaceError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
 	if ne == nil || ne.Err == nil {
 		return []byte("null"), nil
 	}
 
-	ebs, err := ErrorMarshalJSON(ne.Err)
+	ebs, err := errorMarshalJSON(ne.Err, policy)
 	if err != nil {
 		return nil, err
 	}
 
 	output := struct {
+		Name string          `json:"name"`
 		Type string          `json:"type"`
 		Err  json.RawMessage `json:"err"`
 	}{
-		Type: fmt.Sprintf("%T", ne.Err),
+		Name: ne.Name,
+		Type: TypeName(ne.Err),
 		Err:  json.RawMessage(ebs),
 	}
 
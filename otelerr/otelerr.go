@@ -0,0 +1,154 @@
+// Package otelerr records oops errors on OpenTelemetry spans.
+//
+// Each link of an oops.ChainError is recorded as its own exception event (with
+// span.RecordError) with the stacktrace of the innermost oops.TraceError in
+// the link. The span status
+// is set from the class of the error (see oops.Class).
+//
+// ExtractSpan attaches the trace and span IDs of the current span to errors
+// created with oops.NewCtx and oops.TraceCtx once it is registered:
+//
+//	oops.RegisterContextExtractor(otelerr.ExtractSpan)
+package otelerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/calebcase/oops"
+)
+
+// Codes maps oops codes to span status codes. Codes that are not in the map
+// set the status to codes.Error. The default leaves the status unset for
+// errors caused by the caller.
+var Codes = map[oops.Code]codes.Code{
+	oops.CodeCanceled:           codes.Unset,
+	oops.CodeInvalidArgument:    codes.Unset,
+	oops.CodeNotFound:           codes.Unset,
+	oops.CodeAlreadyExists:      codes.Unset,
+	oops.CodeConflict:           codes.Unset,
+	oops.CodePermissionDenied:   codes.Unset,
+	oops.CodeUnauthenticated:    codes.Unset,
+	oops.CodeFailedPrecondition: codes.Unset,
+}
+
+// Status returns the span status code and description for err. The code of
+// err's class is looked up in Codes. The description is the class of err (or
+// the message if it doesn't have one).
+func Status(err error) (codes.Code, string) {
+	if err == nil {
+		return codes.Unset, ""
+	}
+
+	class, ok := oops.ClassOf(err)
+	if !ok {
+		return codes.Error, err.Error()
+	}
+
+	code, ok := Codes[class.Code]
+	if !ok {
+		code = codes.Error
+	}
+
+	if code != codes.Error {
+		// The description is only used with the error code.
+		return code, ""
+	}
+
+	return code, class.Error()
+}
+
+// TypeKey is the attribute with the type of the error below the oops wrappers
+// (see oops.Base). The exception.type set by span.RecordError is the type of
+// the outermost wrapper.
+const TypeKey = attribute.Key("oops.type")
+
+// Record records err on span and sets the span status. Each link of the chain
+// at the top of err (or err itself) is recorded with span.RecordError. The
+// events also have the TypeKey attribute and the exception.stacktrace of the
+// innermost TraceError (if the redaction policy renders frames). The options
+// are passed to each event.
+func Record(span trace.Span, err error, opts ...trace.EventOption) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	for _, link := range links(err) {
+		attrs := []attribute.KeyValue{
+			TypeKey.String(oops.TypeName(oops.Base(link))),
+		}
+
+		fs := innermostFrames(link)
+		if len(fs) > 0 && oops.GetRedactPolicy().Frames == oops.RedactRender {
+			attrs = append(attrs, semconv.ExceptionStacktrace(stacktrace(fs)))
+		}
+
+		eopts := append([]trace.EventOption{}, opts...)
+		eopts = append(eopts, trace.WithAttributes(attrs...))
+
+		span.RecordError(link, eopts...)
+	}
+
+	span.SetStatus(Status(err))
+}
+
+// ExtractSpan is an oops.ContextExtractor that returns the trace and span IDs
+// of the span in ctx as the trace_id and span_id fields.
+func ExtractSpan(ctx context.Context) oops.Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return oops.Fields{
+		{Key: "trace_id", Value: sc.TraceID().String()},
+		{Key: "span_id", Value: sc.SpanID().String()},
+	}
+}
+
+// links returns the links of the chain at the top of err. Wrappers around the
+// chain (e.g. a namespace) are ignored. If there is no chain, then err is the
+// only link.
+func links(err error) []error {
+	if ce, ok := oops.Base(err).(oops.ChainError); ok && len(ce) > 0 {
+		return ce
+	}
+
+	return []error{err}
+}
+
+// innermostFrames returns the frames of the innermost TraceError in err.
+func innermostFrames(err error) (fs oops.Frames) {
+	for err != nil {
+		var te *oops.TraceError
+		if !errors.As(err, &te) {
+			break
+		}
+
+		if tfs := te.Frames(); len(tfs) > 0 {
+			fs = tfs
+		}
+
+		err = te.Err
+	}
+
+	return fs
+}
+
+// stacktrace returns the frames formatted like a Go stack trace.
+func stacktrace(fs oops.Frames) string {
+	b := &strings.Builder{}
+
+	for _, f := range fs {
+		fmt.Fprintf(b, "%s()\n\t%s:%d\n", f.Function, oops.FramePath(f), f.Line)
+	}
+
+	return b.String()
+}
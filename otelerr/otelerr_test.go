@@ -0,0 +1,151 @@
+package otelerr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/calebcase/oops"
+	"github.com/calebcase/oops/otelerr"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	Error = oops.Namespace("otelerr_test")
+
+	ErrNotFound    = Error.Class(oops.CodeNotFound)
+	ErrUnavailable = Error.Class(oops.CodeUnavailable)
+)
+
+func inner() error {
+	return oops.New("inner")
+}
+
+// record records err on a new span and returns the exported span.
+func record(t *testing.T, err error) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	t.Cleanup(func() {
+		tp.Shutdown(context.Background())
+	})
+
+	_, span := tp.Tracer("otelerr_test").Start(context.Background(), "test")
+	otelerr.Record(span, err)
+	span.End()
+
+	spans := exp.GetSpans().Snapshots()
+	require.Len(t, spans, 1)
+
+	return spans[0]
+}
+
+// attrs returns the attributes as a map.
+func attrs(kvs []attribute.KeyValue) map[attribute.Key]string {
+	m := map[attribute.Key]string{}
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value.Emit()
+	}
+
+	return m
+}
+
+func TestRecord(t *testing.T) {
+	t.Run("trace", func(t *testing.T) {
+		err := ErrUnavailable.Wrap(oops.New("backend down"))
+
+		span := record(t, err)
+		require.Equal(t, codes.Error, span.Status().Code)
+		require.Equal(t, "otelerr_test: unavailable", span.Status().Description)
+
+		events := span.Events()
+		require.Len(t, events, 1)
+		require.Equal(t, semconv.ExceptionEventName, events[0].Name)
+
+		as := attrs(events[0].Attributes)
+		require.Equal(t, "*oops.NamespaceError", as[semconv.ExceptionTypeKey])
+		require.Equal(t, "*errors.errorString", as[otelerr.TypeKey])
+		require.Equal(t, "otelerr_test: backend down", as[semconv.ExceptionMessageKey])
+
+		st := as[semconv.ExceptionStacktraceKey]
+		require.True(t, strings.HasPrefix(st, "github.com/calebcase/oops/otelerr_test.TestRecord.func1()\n\t"), st)
+	})
+
+	t.Run("innermost", func(t *testing.T) {
+		err := oops.Trace(inner())
+
+		span := record(t, err)
+
+		as := attrs(span.Events()[0].Attributes)
+		st := as[semconv.ExceptionStacktraceKey]
+		require.True(t, strings.HasPrefix(st, "github.com/calebcase/oops/otelerr_test.inner()\n\t"), st)
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := Error.Chain(
+			oops.New("write failed"),
+			ErrNotFound.Wrap(io.EOF),
+		)
+
+		span := record(t, err)
+		require.Equal(t, codes.Unset, span.Status().Code)
+
+		events := span.Events()
+		require.Len(t, events, 2)
+
+		first := attrs(events[0].Attributes)
+		require.Equal(t, "*oops.TraceError", first[semconv.ExceptionTypeKey])
+		require.Equal(t, "*errors.errorString", first[otelerr.TypeKey])
+		require.Equal(t, "write failed", first[semconv.ExceptionMessageKey])
+		require.Contains(t, first[semconv.ExceptionStacktraceKey], "TestRecord.func3()")
+
+		second := attrs(events[1].Attributes)
+		require.Equal(t, "*oops.NamespaceError", second[semconv.ExceptionTypeKey])
+		require.Equal(t, "*errors.errorString", second[otelerr.TypeKey])
+		require.Equal(t, "otelerr_test: EOF", second[semconv.ExceptionMessageKey])
+		require.NotContains(t, second, semconv.ExceptionStacktraceKey)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		span := record(t, nil)
+		require.Equal(t, codes.Unset, span.Status().Code)
+		require.Empty(t, span.Events())
+	})
+}
+
+func TestStatus(t *testing.T) {
+	code, desc := otelerr.Status(errors.New("plain"))
+	require.Equal(t, codes.Error, code)
+	require.Equal(t, "plain", desc)
+
+	code, desc = otelerr.Status(ErrNotFound.New("missing"))
+	require.Equal(t, codes.Unset, code)
+	require.Equal(t, "", desc)
+}
+
+func TestExtractSpan(t *testing.T) {
+	require.Nil(t, otelerr.ExtractSpan(context.Background()))
+
+	tp := sdktrace.NewTracerProvider()
+	t.Cleanup(func() {
+		tp.Shutdown(context.Background())
+	})
+
+	ctx, span := tp.Tracer("otelerr_test").Start(context.Background(), "test")
+	defer span.End()
+
+	sc := span.SpanContext()
+	require.Equal(t, oops.Fields{
+		{Key: "trace_id", Value: sc.TraceID().String()},
+		{Key: "span_id", Value: sc.SpanID().String()},
+	}, otelerr.ExtractSpan(ctx))
+}
@@ -0,0 +1,149 @@
+package oops
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PanicError is an error created from a recovered panic.
+type PanicError struct {
+	Value any
+}
+
+var (
+	_ error     = &PanicError{}
+	_ unwrapper = &PanicError{}
+)
+
+// Error implements error.
+func (pe *PanicError) Error() string {
+	if pe == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("panic: %v", pe.Value)
+}
+
+// Unwrap implements the implied interface for errors.Unwrap. If the panic
+// value is an error, then it is returned.
+func (pe *PanicError) Unwrap() error {
+	if pe == nil {
+		return nil
+	}
+
+	err, _ := pe.Value.(error)
+
+	return err
+}
+
+// MarshalJSON implements json.Marshaler. If the panic value is an error, then
+// it is included like the errors of the other wrappers.
+func (pe *PanicError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(pe)
+}
+
+// marshalJSON implements policyMarshaler.
+func (pe *PanicError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
+	if pe == nil {
+		return []byte("null"), nil
+	}
+
+	output := struct {
+		ValueType string          `json:"value_type"`
+		Value     string          `json:"value"`
+		Type      string          `json:"type,omitempty"`
+		Err       json.RawMessage `json:"err,omitempty"`
+	}{
+		ValueType: fmt.Sprintf("%T", pe.Value),
+		Value:     fmt.Sprintf("%v", pe.Value),
+	}
+
+	if e, ok := pe.Value.(error); ok {
+		ebs, err := errorMarshalJSON(e, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		output.Type = TypeName(e)
+		output.Err = json.RawMessage(ebs)
+	}
+
+	return json.Marshal(output)
+}
+
+// recoverSkip is the number of frames to skip for the capture in recovered so
+// that the stack starts at the deferred recovering function.
+const recoverSkip = 4
+
+// Recover recovers from a panic and chains a traced PanicError onto err. The
+// trace starts at the site of the panic. If err is nil, then the panic is
+// continued. It must be deferred directly:
+//
+//	defer oops.Recover(&err)
+func Recover(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	recovered(err, r)
+}
+
+// RecoverF returns a function that recovers from a panic and chains a traced
+// PanicError onto err. If err is nil, then the panic is continued. It must be
+// deferred directly:
+//
+//	defer oops.RecoverF(&err)()
+func RecoverF(err *error) func() {
+	return func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		recovered(err, r)
+	}
+}
+
+// recovered chains the traced panic onto err. It must only be called directly
+// from the deferred recovering function. There is nowhere to record the panic
+// if err is nil so it is raised again.
+func recovered(err *error, r any) {
+	if err == nil {
+		panic(r)
+	}
+
+	ChainP(err, &TraceError{
+		Data: CapturePanicFrames(nil, recoverSkip),
+		Err: &PanicError{
+			Value: r,
+		},
+	})
+}
+
+// CapturePanicFrames returns the captured stack as Frames starting at the site
+// of the panic. The frames of the runtime panic handling (and anything that
+// was called from it) are removed as are the frames of helpers (see Helper)
+// at the site. If there is no panic in progress, then the stack is the same as
+// from CaptureFrames without the helpers.
+func CapturePanicFrames(err error, skip int) (data Frames) {
+	fs := Frames(CaptureRuntimeFrames(err, skip))
+
+	for i, f := range fs {
+		if f.Function != "runtime.gopanic" {
+			continue
+		}
+
+		// Runtime errors (e.g. nil pointer dereferences) are raised by
+		// runtime functions that sit between the panic and the site.
+		i++
+		for i < len(fs) && strings.HasPrefix(fs[i].Function, "runtime.") {
+			i++
+		}
+
+		return trimHelpers(fs[i:])
+	}
+
+	return trimHelpers(fs)
+}
@@ -0,0 +1,180 @@
+package oops_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+var ErrPanic = errors.New("panic error")
+
+func panicValue(v any) {
+	panic(v)
+}
+
+func panicNil() int {
+	var p *int
+
+	return *p
+}
+
+func recoverValue(v any) (err error) {
+	defer oops.Recover(&err)
+
+	panicValue(v)
+
+	return nil
+}
+
+func recoverValueF(v any) (err error) {
+	defer oops.RecoverF(&err)()
+
+	panicValue(v)
+
+	return nil
+}
+
+func recoverChain() (err error) {
+	defer oops.Recover(&err)
+	defer func() {
+		err = oops.New("before panic")
+		panicValue("after error")
+	}()
+
+	return nil
+}
+
+func recoverNil() (err error) {
+	defer oops.Recover(&err)
+
+	panicNil()
+
+	return nil
+}
+
+func recoverNone() (err error) {
+	defer oops.Recover(&err)
+
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	t.Run("none", func(t *testing.T) {
+		require.NoError(t, recoverNone())
+	})
+
+	fns := []struct {
+		Name string
+		Fn   func(any) error
+	}{
+		{"Recover", recoverValue},
+		{"RecoverF", recoverValueF},
+	}
+
+	for _, tc := range fns {
+		fn := tc.Fn
+
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Run("value", func(t *testing.T) {
+				err := fn("bad stuff")
+				require.Error(t, err)
+				t.Logf("%+v", err)
+
+				require.Equal(t, "panic: bad stuff", err.Error())
+
+				pe := &oops.PanicError{}
+				require.ErrorAs(t, err, &pe)
+				require.Equal(t, "bad stuff", pe.Value)
+
+				te := &oops.TraceError{}
+				require.ErrorAs(t, err, &te)
+				require.Equal(t, "github.com/calebcase/oops_test.panicValue", te.Frames()[0].Function)
+			})
+
+			t.Run("error", func(t *testing.T) {
+				err := fn(ErrPanic)
+				require.ErrorIs(t, err, ErrPanic)
+			})
+		})
+	}
+
+	t.Run("runtime", func(t *testing.T) {
+		err := recoverNil()
+		require.Error(t, err)
+		t.Logf("%+v", err)
+
+		te := &oops.TraceError{}
+		require.ErrorAs(t, err, &te)
+		require.Equal(t, "github.com/calebcase/oops_test.panicNil", te.Frames()[0].Function)
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := recoverChain()
+		require.Error(t, err)
+		t.Logf("%+v", err)
+
+		ce := err.(oops.ChainError)
+		require.Len(t, ce, 2)
+		require.Equal(t, "before panic", ce[0].Error())
+		require.Equal(t, "panic: after error", ce[1].Error())
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.PanicsWithValue(t, "bad stuff", func() {
+			defer oops.Recover(nil)
+
+			panicValue("bad stuff")
+		})
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		pe := &oops.PanicError{Value: 42}
+
+		bs, err := oops.ErrorMarshalJSON(pe)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"value_type":"int","value":"42","fingerprint":"`+oops.Fingerprint(pe)+`"}`, string(bs))
+
+		pe = &oops.PanicError{Value: io.EOF}
+
+		bs, err = oops.ErrorMarshalJSON(pe)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"value_type":"*errors.errorString","value":"EOF","type":"*errors.errorString","err":"EOF","fingerprint":"`+oops.Fingerprint(pe)+`"}`, string(bs))
+	})
+
+	t.Run("UnmarshalError", func(t *testing.T) {
+		oops.RegisterSentinel(io.EOF)
+
+		orig := recoverValue(io.EOF)
+
+		bs, err := oops.MarshalError(orig)
+		require.NoError(t, err)
+		t.Log(string(bs))
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+		require.Equal(t, orig.Error(), derr.Error())
+		require.ErrorIs(t, derr, io.EOF)
+
+		pe := &oops.PanicError{}
+		require.ErrorAs(t, derr, &pe)
+
+		derr, err = oops.UnmarshalError([]byte(`{"value_type":"int","value":"42"}`))
+		require.NoError(t, err)
+		require.Equal(t, &oops.PanicError{Value: "42"}, derr)
+	})
+}
+
+func ExampleRecover() {
+	work := func() (err error) {
+		defer oops.Recover(&err)
+
+		panic("bad stuff")
+	}
+
+	fmt.Println(work())
+	// Output: panic: bad stuff
+}
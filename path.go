@@ -0,0 +1,143 @@
+package oops
+
+import (
+	"path"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// PathRewriter rewrites the file path of a frame when it is output. If the
+// rewriter doesn't apply to the frame, then it returns false.
+type PathRewriter func(f runtime.Frame) (file string, ok bool)
+
+// pathRewriters is the package level setting for path rewriters.
+var pathRewriters atomic.Pointer[[]PathRewriter]
+
+// SetPathRewriters changes the package level path rewriters. They are applied
+// to the file paths of frames in Frames.String (and so %+v), in the JSON
+// output, and in slog values. The first rewriter that applies to a frame is
+// used. By default the paths are output as captured. It is safe to call while
+// errors are being output.
+//
+//	oops.SetPathRewriters(oops.TrimModules(), oops.TrimGOROOT())
+func SetPathRewriters(rs ...PathRewriter) {
+	pathRewriters.Store(&rs)
+}
+
+// getPathRewriters returns the package level path rewriters.
+func getPathRewriters() []PathRewriter {
+	if rs := pathRewriters.Load(); rs != nil {
+		return *rs
+	}
+
+	return nil
+}
+
+// FramePath returns the file path of the frame after applying the package
+// level path rewriters.
+func FramePath(f runtime.Frame) string {
+	for _, r := range getPathRewriters() {
+		if file, ok := r(f); ok {
+			return file
+		}
+	}
+
+	return f.File
+}
+
+// ReplacePrefix returns a rewriter that replaces the prefix old with new.
+func ReplacePrefix(old, new string) PathRewriter {
+	return func(f runtime.Frame) (string, bool) {
+		rest, ok := strings.CutPrefix(f.File, old)
+		if !ok {
+			return "", false
+		}
+
+		return new + rest, true
+	}
+}
+
+// TrimGOROOT returns a rewriter that replaces the GOROOT directory with
+// "$GOROOT" in the paths of standard library frames.
+func TrimGOROOT() PathRewriter {
+	return func(f runtime.Frame) (string, bool) {
+		pkg := framePackage(f.Function)
+
+		first, _, _ := strings.Cut(pkg, "/")
+		if first == "" || first == "main" || strings.Contains(first, ".") {
+			return "", false
+		}
+
+		i := strings.LastIndex(f.File, "/src/"+pkg+"/")
+		if i < 0 {
+			return "", false
+		}
+
+		return "$GOROOT" + f.File[i:], true
+	}
+}
+
+// TrimModules returns a rewriter that replaces the module directory with the
+// module path (and version for dependencies) in the paths of frames from the
+// main module and its dependencies. The modules are found with
+// debug.ReadBuildInfo. For example:
+//
+//	/home/ci/go/pkg/mod/github.com/you/dep@v1.2.3/dep.go
+//
+// Becomes:
+//
+//	github.com/you/dep@v1.2.3/dep.go
+func TrimModules() PathRewriter {
+	type module struct {
+		path    string
+		version string
+	}
+
+	modules := []module{}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Path != "" {
+			modules = append(modules, module{path: bi.Main.Path})
+		}
+
+		for _, dep := range bi.Deps {
+			modules = append(modules, module{path: dep.Path, version: dep.Version})
+		}
+	}
+
+	// Prefer the longest module path for nested modules.
+	sort.Slice(modules, func(i, j int) bool {
+		return len(modules[i].path) > len(modules[j].path)
+	})
+
+	return func(f runtime.Frame) (string, bool) {
+		// External test packages are in the directory of the package
+		// they test.
+		pkg := strings.TrimSuffix(framePackage(f.Function), "_test")
+
+		for _, m := range modules {
+			sub, ok := strings.CutPrefix(pkg, m.path)
+			if !ok || (sub != "" && sub[0] != '/') {
+				continue
+			}
+
+			dir := path.Dir(f.File)
+			root, ok := strings.CutSuffix(dir, sub)
+			if !ok {
+				return "", false
+			}
+
+			name := m.path
+			if m.version != "" && m.version != "(devel)" {
+				name += "@" + m.version
+			}
+
+			return name + f.File[len(root):], true
+		}
+
+		return "", false
+	}
+}
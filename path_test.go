@@ -0,0 +1,151 @@
+package oops_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func setPathRewriters(t *testing.T, rs ...oops.PathRewriter) {
+	oops.SetPathRewriters(rs...)
+	t.Cleanup(func() {
+		oops.SetPathRewriters()
+	})
+}
+
+func TestPathRewriters(t *testing.T) {
+	version := ""
+
+	bi, ok := debug.ReadBuildInfo()
+	require.True(t, ok)
+
+	for _, dep := range bi.Deps {
+		if dep.Path == "github.com/stretchr/testify" {
+			version = dep.Version
+		}
+	}
+
+	type TC struct {
+		Name     string
+		Rewriter oops.PathRewriter
+		Frame    runtime.Frame
+		Expect   string
+		OK       bool
+	}
+
+	tcs := []TC{
+		{
+			Name:     "ReplacePrefix",
+			Rewriter: oops.ReplacePrefix("/home/ci/src/", "src/"),
+			Frame:    runtime.Frame{Function: "main.main", File: "/home/ci/src/app/main.go"},
+			Expect:   "src/app/main.go",
+			OK:       true,
+		},
+		{
+			Name:     "ReplacePrefix no match",
+			Rewriter: oops.ReplacePrefix("/home/ci/src/", "src/"),
+			Frame:    runtime.Frame{Function: "main.main", File: "/build/app/main.go"},
+		},
+		{
+			Name:     "TrimGOROOT",
+			Rewriter: oops.TrimGOROOT(),
+			Frame:    runtime.Frame{Function: "net/http.HandlerFunc.ServeHTTP", File: "/usr/local/go/src/net/http/server.go"},
+			Expect:   "$GOROOT/src/net/http/server.go",
+			OK:       true,
+		},
+		{
+			Name:     "TrimGOROOT not std",
+			Rewriter: oops.TrimGOROOT(),
+			Frame:    runtime.Frame{Function: "github.com/you/app.Run", File: "/src/github.com/you/app/app.go"},
+		},
+		{
+			Name:     "TrimModules main",
+			Rewriter: oops.TrimModules(),
+			Frame:    runtime.Frame{Function: "github.com/calebcase/oops/lines.Indent", File: "/home/ci/oops/lines/lines.go"},
+			Expect:   "github.com/calebcase/oops/lines/lines.go",
+			OK:       true,
+		},
+		{
+			Name:     "TrimModules dependency",
+			Rewriter: oops.TrimModules(),
+			Frame: runtime.Frame{
+				Function: "github.com/stretchr/testify/require.Equal",
+				File:     "/home/ci/go/pkg/mod/github.com/stretchr/testify@" + version + "/require/require.go",
+			},
+			Expect: "github.com/stretchr/testify@" + version + "/require/require.go",
+			OK:     true,
+		},
+		{
+			Name:     "TrimModules unknown",
+			Rewriter: oops.TrimModules(),
+			Frame:    runtime.Frame{Function: "github.com/you/app.Run", File: "/src/app/app.go"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			file, ok := tc.Rewriter(tc.Frame)
+			require.Equal(t, tc.OK, ok)
+			require.Equal(t, tc.Expect, file)
+		})
+	}
+}
+
+func TestPathRewritersOutput(t *testing.T) {
+	setPathRewriters(t, oops.TrimModules(), oops.TrimGOROOT())
+
+	err := oops.New("bad stuff")
+	fs := err.(*oops.TraceError).Frames()
+	require.Equal(t, "github.com/calebcase/oops_test.TestPathRewritersOutput", fs[0].Function)
+	require.Equal(t, "testing.tRunner", fs[1].Function)
+
+	local := fmt.Sprintf("github.com/calebcase/oops/path_test.go:%d", fs[0].Line)
+	std := fmt.Sprintf("$GOROOT/src/testing/testing.go:%d", fs[1].Line)
+
+	output := fmt.Sprintf("%+v", err)
+	require.Contains(t, output, local)
+	require.Contains(t, output, std)
+
+	require.Contains(t, fs.String(), local)
+
+	bs, err := json.Marshal(err)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Data []runtime.Frame `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(bs, &decoded))
+	require.Equal(t, "github.com/calebcase/oops/path_test.go", decoded.Data[0].File)
+	require.Equal(t, "$GOROOT/src/testing/testing.go", decoded.Data[1].File)
+
+	// The captured frames are unchanged.
+	require.NotEqual(t, "github.com/calebcase/oops/path_test.go", fs[0].File)
+}
+
+func TestPathRewritersConcurrent(t *testing.T) {
+	defer oops.SetPathRewriters()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			oops.SetPathRewriters(oops.TrimGOROOT())
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			require.NotEmpty(t, fmt.Sprintf("%+v", oops.New("bad stuff")))
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,301 @@
+package oops
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Redacted replaces data that is masked by the redaction policy.
+const Redacted = "[REDACTED]"
+
+// RedactAction is what the redaction policy does with sensitive data.
+type RedactAction int
+
+const (
+	// RedactDefault is the zero action. Sensitive values are masked and all
+	// other data is rendered.
+	RedactDefault RedactAction = iota
+
+	// RedactRender renders the data as is.
+	RedactRender
+
+	// RedactMask replaces the data with Redacted.
+	RedactMask
+
+	// RedactDrop omits the data.
+	RedactDrop
+)
+
+// RedactPolicy decides how sensitive data is rendered by Format (%+v),
+// MarshalJSON, and SlogValue. Actions that are not set use RedactDefault.
+type RedactPolicy struct {
+	// Hidden applies to the hidden errors of ShadowErrors. Hidden errors
+	// are never included by MarshalJSON.
+	Hidden RedactAction
+
+	// Frames applies to the trace data of TraceErrors.
+	Frames RedactAction
+
+	// Field returns the action for the field with the given key. If nil,
+	// then all fields are rendered.
+	Field func(key string) RedactAction
+
+	// Sensitive applies to values wrapped with Sensitive.
+	Sensitive RedactAction
+}
+
+// field returns the action for the field with the given key.
+func (rp *RedactPolicy) field(key string) RedactAction {
+	if rp.Field == nil {
+		return RedactRender
+	}
+
+	if action := rp.Field(key); action != RedactDefault {
+		return action
+	}
+
+	return RedactRender
+}
+
+// resolve returns the policy with the actions that are RedactDefault
+// replaced by what they mean for the data.
+func (rp RedactPolicy) resolve() *RedactPolicy {
+	if rp.Hidden == RedactDefault {
+		rp.Hidden = RedactRender
+	}
+
+	if rp.Frames == RedactDefault {
+		rp.Frames = RedactRender
+	}
+
+	if rp.Sensitive == RedactDefault {
+		rp.Sensitive = RedactMask
+	}
+
+	return &rp
+}
+
+// RedactFields returns a RedactPolicy.Field function that applies action to
+// the fields with the given keys and renders all others.
+func RedactFields(action RedactAction, keys ...string) func(key string) RedactAction {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+
+	return func(key string) RedactAction {
+		if set[key] {
+			return action
+		}
+
+		return RedactRender
+	}
+}
+
+// redactPolicy is the package level redaction policy.
+var redactPolicy atomic.Pointer[RedactPolicy]
+
+func init() {
+	SetRedactPolicy(RedactPolicy{})
+}
+
+// SetRedactPolicy changes the package level redaction policy. The default
+// policy renders everything except for Sensitive values which are masked. Use
+// Redact to apply a different policy to a single error.
+func SetRedactPolicy(p RedactPolicy) {
+	redactPolicy.Store(p.resolve())
+}
+
+// GetRedactPolicy returns the package level redaction policy.
+func GetRedactPolicy() RedactPolicy {
+	return *redactPolicy.Load()
+}
+
+// RedactError is an error that is formatted, marshalled, and converted to a
+// slog value with its own redaction policy instead of the package level one.
+type RedactError struct {
+	Policy RedactPolicy
+	Err    error
+}
+
+var (
+	_ error          = &RedactError{}
+	_ unwrapper      = &RedactError{}
+	_ slog.LogValuer = &RedactError{}
+)
+
+// Error implements error.
+func (re *RedactError) Error() string {
+	return fmt.Sprintf("%v", re)
+}
+
+// Unwrap implements the implied interface for errors.Unwrap.
+func (re *RedactError) Unwrap() error {
+	if re == nil || re.Err == nil {
+		return nil
+	}
+
+	return re.Err
+}
+
+// Format implements fmt.Format. The error is formatted as the error it wraps.
+func (re *RedactError) Format(f fmt.State, verb rune) {
+	re.formatRelative(f, verb, nil, nil)
+}
+
+// formatRelative implements relativeFormatter. The given policy is replaced
+// by the error's policy.
+func (re *RedactError) formatRelative(f fmt.State, verb rune, ref Frames, _ *RedactPolicy) {
+	if re == nil || re.Err == nil {
+		fmt.Fprintf(f, "<nil>")
+
+		return
+	}
+
+	relativeTo{re.Err, ref, re.Policy.resolve()}.Format(f, verb)
+}
+
+// MarshalJSON implements json.Marshaler. The error is marshalled as the error
+// it wraps.
+func (re *RedactError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(re)
+}
+
+// marshalJSON implements policyMarshaler. The given policy is replaced by the
+// error's policy.
+func (re *RedactError) marshalJSON(_ *RedactPolicy) (bs []byte, err error) {
+	if re == nil || re.Err == nil {
+		return []byte("null"), nil
+	}
+
+	return errorMarshalJSON(re.Err, re.Policy.resolve())
+}
+
+// LogValue implements slog.LogValuer.
+func (re *RedactError) LogValue() slog.Value {
+	return SlogValue(re, SlogOptions{})
+}
+
+// Redact returns err with the redaction policy p used in place of the package
+// level policy when it is formatted (%+v), marshalled, or converted to a slog
+// value:
+//
+//	log.Printf("%+v", oops.Redact(err, oops.RedactPolicy{Hidden: oops.RedactDrop}))
+func Redact(err error, p RedactPolicy) error {
+	if err == nil {
+		return nil
+	}
+
+	return &RedactError{
+		Policy: p,
+		Err:    err,
+	}
+}
+
+// SensitiveValue is a value that is only rendered if the redaction policy
+// allows it.
+type SensitiveValue[T any] struct {
+	Value T
+}
+
+// Sensitive wraps v so that it is rendered according to the Sensitive action
+// of the redaction policy. Masked values render as Redacted and dropped values
+// render as empty (or null in JSON).
+//
+// Note that the policy is applied when the value is rendered: a value passed
+// to New or fmt.Errorf is rendered when the error is created.
+func Sensitive[T any](v T) SensitiveValue[T] {
+	return SensitiveValue[T]{
+		Value: v,
+	}
+}
+
+// Format implements fmt.Format.
+func (sv SensitiveValue[T]) Format(f fmt.State, verb rune) {
+	sv.redact(redactPolicy.Load()).Format(f, verb)
+}
+
+// String implements fmt.Stringer.
+func (sv SensitiveValue[T]) String() string {
+	return fmt.Sprintf("%v", sv)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (sv SensitiveValue[T]) MarshalJSON() ([]byte, error) {
+	return sv.redact(redactPolicy.Load()).MarshalJSON()
+}
+
+// LogValue implements slog.LogValuer.
+func (sv SensitiveValue[T]) LogValue() slog.Value {
+	return sv.redact(redactPolicy.Load()).LogValue()
+}
+
+// redact implements redacter.
+func (sv SensitiveValue[T]) redact(policy *RedactPolicy) redactedValue {
+	action := policy.Sensitive
+	if action == RedactDefault {
+		action = RedactMask
+	}
+
+	return redactedValue{
+		value:  sv.Value,
+		action: action,
+	}
+}
+
+// redacter is implemented by values that depend on the redaction policy.
+type redacter interface {
+	redact(policy *RedactPolicy) redactedValue
+}
+
+// redactValue applies the redaction policy to v if it is a SensitiveValue.
+// Other values are returned unchanged.
+func redactValue(v any, policy *RedactPolicy) any {
+	if r, ok := v.(redacter); ok {
+		return r.redact(policy)
+	}
+
+	return v
+}
+
+// redactedValue is a sensitive value with the action of a redaction policy.
+type redactedValue struct {
+	value  any
+	action RedactAction
+}
+
+// Format implements fmt.Format.
+func (rv redactedValue) Format(f fmt.State, verb rune) {
+	switch rv.action {
+	case RedactRender:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), rv.value)
+	case RedactMask:
+		fmt.Fprint(f, Redacted)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (rv redactedValue) MarshalJSON() ([]byte, error) {
+	switch rv.action {
+	case RedactRender:
+		return json.Marshal(rv.value)
+	case RedactMask:
+		return json.Marshal(Redacted)
+	}
+
+	return []byte("null"), nil
+}
+
+// LogValue implements slog.LogValuer.
+func (rv redactedValue) LogValue() slog.Value {
+	switch rv.action {
+	case RedactRender:
+		return slog.AnyValue(rv.value)
+	case RedactMask:
+		return slog.StringValue(Redacted)
+	}
+
+	return slog.Value{}
+}
@@ -0,0 +1,268 @@
+package oops_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func setRedactPolicy(t *testing.T, p oops.RedactPolicy) {
+	t.Helper()
+
+	orig := oops.GetRedactPolicy()
+	t.Cleanup(func() {
+		oops.SetRedactPolicy(orig)
+	})
+
+	oops.SetRedactPolicy(p)
+}
+
+func TestRedactPolicy(t *testing.T) {
+	newErr := func() error {
+		return oops.With(
+			oops.Shadow(
+				errors.New("password=hunter2"),
+				oops.New("login failed"),
+			),
+			"user", "alice",
+			"token", "s3cr3t",
+		)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		output := fmt.Sprintf("%+v", newErr())
+		t.Log(output)
+
+		require.Contains(t, output, "hidden: password=hunter2")
+		require.Contains(t, output, "TestRedactPolicy")
+		require.Contains(t, output, "token: s3cr3t")
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		setRedactPolicy(t, oops.RedactPolicy{
+			Hidden: oops.RedactMask,
+			Frames: oops.RedactMask,
+			Field:  oops.RedactFields(oops.RedactMask, "token"),
+		})
+
+		err := newErr()
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		require.Contains(t, output, "hidden: [REDACTED]")
+		require.NotContains(t, output, "hunter2")
+		require.NotContains(t, output, "TestRedactPolicy")
+		require.Contains(t, output, "token: [REDACTED]")
+		require.Contains(t, output, "user: alice")
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+		t.Log(string(bs))
+
+		require.Contains(t, string(bs), `"data":"[REDACTED]"`)
+		require.Contains(t, string(bs), `"fields":{"user":"alice","token":"[REDACTED]"}`)
+
+		buf := &bytes.Buffer{}
+		h := oops.NewSlogHandler(slog.NewJSONHandler(buf, nil), oops.SlogOptions{Hidden: true})
+		slog.New(h).Error("failed", "err", err)
+		t.Log(buf.String())
+
+		require.NotContains(t, buf.String(), "hunter2")
+		require.NotContains(t, buf.String(), "s3cr3t")
+		require.Contains(t, buf.String(), `"hidden":"[REDACTED]"`)
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		setRedactPolicy(t, oops.RedactPolicy{
+			Hidden: oops.RedactDrop,
+			Frames: oops.RedactDrop,
+			Field:  oops.RedactFields(oops.RedactDrop, "token"),
+		})
+
+		err := newErr()
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		require.NotContains(t, output, "hidden")
+		require.NotContains(t, output, "TestRedactPolicy")
+		require.NotContains(t, output, "token")
+
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+		t.Log(string(bs))
+
+		require.Contains(t, string(bs), `"data":null`)
+		require.NotContains(t, string(bs), "TestRedactPolicy")
+		require.Contains(t, string(bs), `"fields":{"user":"alice"}`)
+	})
+}
+
+func TestRedact(t *testing.T) {
+	newErr := func() error {
+		return oops.With(
+			oops.Shadow(
+				errors.New("password=hunter2"),
+				oops.New("login failed"),
+			),
+			"user", "alice",
+			"token", "s3cr3t",
+			"email", oops.Sensitive("alice@example.com"),
+		)
+	}
+
+	policy := oops.RedactPolicy{
+		Hidden:    oops.RedactMask,
+		Frames:    oops.RedactDrop,
+		Field:     oops.RedactFields(oops.RedactMask, "token"),
+		Sensitive: oops.RedactRender,
+	}
+
+	err := newErr()
+	rerr := oops.Redact(err, policy)
+
+	require.Equal(t, err.Error(), rerr.Error())
+	require.Equal(t, oops.Fingerprint(err), oops.Fingerprint(rerr))
+	require.Nil(t, oops.Redact(nil, policy))
+
+	t.Run("Format", func(t *testing.T) {
+		output := fmt.Sprintf("%+v", rerr)
+		t.Log(output)
+
+		require.Contains(t, output, "hidden: [REDACTED]")
+		require.NotContains(t, output, "hunter2")
+		require.NotContains(t, output, "TestRedact")
+		require.Contains(t, output, "token: [REDACTED]")
+		require.Contains(t, output, "email: alice@example.com")
+
+		// The package level policy is unchanged.
+		output = fmt.Sprintf("%+v", err)
+		require.Contains(t, output, "hunter2")
+		require.Contains(t, output, "email: [REDACTED]")
+
+		// The policy applies to the errors below it in a tree.
+		output = fmt.Sprintf("%+v", oops.Chain(oops.New("first"), rerr))
+		t.Log(output)
+
+		require.Contains(t, output, "TestRedact")
+		require.NotContains(t, output, "hunter2")
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		bs, jerr := json.Marshal(rerr)
+		require.NoError(t, jerr)
+		t.Log(string(bs))
+
+		require.Contains(t, string(bs), `"type":"*oops.ShadowError"`)
+		require.Contains(t, string(bs), `"data":null`)
+		require.Contains(t, string(bs), `"fields":{"user":"alice","token":"[REDACTED]","email":"alice@example.com"}`)
+
+		bs, jerr = oops.MarshalError(rerr)
+		require.NoError(t, jerr)
+
+		derr, jerr := oops.UnmarshalError(bs)
+		require.NoError(t, jerr)
+		require.IsType(t, &oops.FieldsError{}, derr)
+		require.Equal(t, err.Error(), derr.Error())
+	})
+
+	t.Run("SlogValue", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slog.NewJSONHandler(buf, nil))
+		logger.Error("failed", "err", rerr)
+		t.Log(buf.String())
+
+		require.Contains(t, buf.String(), `"token":"[REDACTED]"`)
+		require.Contains(t, buf.String(), `"email":"alice@example.com"`)
+		require.NotContains(t, buf.String(), "TestRedact")
+
+		buf.Reset()
+		logger = slog.New(oops.NewSlogHandler(slog.NewJSONHandler(buf, nil), oops.SlogOptions{
+			Hidden: true,
+			Policy: &policy,
+		}))
+		logger.Error("failed", "err", err)
+		t.Log(buf.String())
+
+		require.Contains(t, buf.String(), `"hidden":"[REDACTED]"`)
+		require.Contains(t, buf.String(), `"token":"[REDACTED]"`)
+	})
+}
+
+func TestSensitive(t *testing.T) {
+	type TC struct {
+		Action oops.RedactAction
+		Format string
+		JSON   string
+	}
+
+	tcs := []TC{
+		{Action: oops.RedactRender, Format: "email=alice@example.com", JSON: `"alice@example.com"`},
+		{Action: oops.RedactMask, Format: "email=[REDACTED]", JSON: `"[REDACTED]"`},
+		{Action: oops.RedactDrop, Format: "email=", JSON: `null`},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprint(tc.Action), func(t *testing.T) {
+			setRedactPolicy(t, oops.RedactPolicy{
+				Sensitive: tc.Action,
+			})
+
+			v := oops.Sensitive("alice@example.com")
+
+			require.Equal(t, tc.Format, fmt.Sprintf("email=%v", v))
+			require.Equal(t, tc.Format, "email="+v.String())
+
+			bs, err := json.Marshal(v)
+			require.NoError(t, err)
+			require.Equal(t, tc.JSON, string(bs))
+		})
+	}
+
+	t.Run("default", func(t *testing.T) {
+		err := oops.With(oops.New("user %v", oops.Sensitive("alice")), "email", oops.Sensitive("alice@example.com"))
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		require.NotContains(t, output, "alice")
+		require.Contains(t, output, "email: [REDACTED]")
+	})
+
+	t.Run("partial policy", func(t *testing.T) {
+		err := oops.With(oops.New("bad stuff"), "password", oops.Sensitive("hunter2"))
+
+		rerr := oops.Redact(err, oops.RedactPolicy{Hidden: oops.RedactDrop})
+
+		output := fmt.Sprintf("%+v", rerr)
+		t.Log(output)
+		require.Contains(t, output, "password: [REDACTED]")
+		require.Contains(t, output, "TestSensitive")
+
+		bs, jerr := json.Marshal(rerr)
+		require.NoError(t, jerr)
+		require.Contains(t, string(bs), `"fields":{"password":"[REDACTED]"}`)
+
+		buf := &bytes.Buffer{}
+		logger := slog.New(oops.NewSlogHandler(slog.NewJSONHandler(buf, nil), oops.SlogOptions{
+			Policy: &oops.RedactPolicy{Hidden: oops.RedactDrop},
+		}))
+		logger.Error("failed", "err", err)
+		require.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+
+		setRedactPolicy(t, oops.RedactPolicy{Frames: oops.RedactDrop})
+		require.Equal(t, oops.RedactMask, oops.GetRedactPolicy().Sensitive)
+
+		output = fmt.Sprintf("%+v", err)
+		t.Log(output)
+		require.Contains(t, output, "password: [REDACTED]")
+		require.NotContains(t, output, "TestSensitive")
+	})
+}
@@ -0,0 +1,199 @@
+package oops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampler decides whether a capture is taken. The pc is the program counter
+// of the call site of the trace (e.g. where New was called).
+type Sampler interface {
+	Sample(pc uintptr) bool
+}
+
+// SamplerFunc defines a Sampler that calls the given function.
+type SamplerFunc func(pc uintptr) bool
+
+// Sample implements Sampler.
+func (sf SamplerFunc) Sample(pc uintptr) bool {
+	return sf(pc)
+}
+
+// SampleEvery returns a sampler that takes 1 in every n captures (starting
+// with the first).
+func SampleEvery(n int) Sampler {
+	var count atomic.Uint64
+
+	return SamplerFunc(func(uintptr) bool {
+		if n <= 1 {
+			return true
+		}
+
+		return (count.Add(1)-1)%uint64(n) == 0
+	})
+}
+
+// SampleCallSite returns a sampler that limits the captures of each call site
+// with a token bucket. Each call site may burst up to burst captures and then
+// takes rate captures per second.
+func SampleCallSite(rate float64, burst int) Sampler {
+	type bucket struct {
+		mu     sync.Mutex
+		tokens float64
+		last   time.Time
+	}
+
+	var buckets sync.Map
+
+	return SamplerFunc(func(pc uintptr) bool {
+		now := time.Now()
+
+		v, ok := buckets.Load(pc)
+		if !ok {
+			v, _ = buckets.LoadOrStore(pc, &bucket{
+				tokens: float64(burst),
+				last:   now,
+			})
+		}
+
+		b := v.(*bucket)
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*rate, float64(burst))
+		b.last = now
+
+		if b.tokens < 1 {
+			return false
+		}
+
+		b.tokens--
+
+		return true
+	})
+}
+
+// SampleWindow returns a sampler that takes at most n captures in each window
+// of time.
+func SampleWindow(window time.Duration, n int) Sampler {
+	var mu sync.Mutex
+	var start time.Time
+	var count int
+
+	return SamplerFunc(func(uintptr) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		if now.Sub(start) >= window {
+			start = now
+			count = 0
+		}
+
+		if count >= n {
+			return false
+		}
+
+		count++
+
+		return true
+	})
+}
+
+// SampledOut is the trace data recorded when a capture is skipped by
+// CaptureSampled. Only the call site is recorded.
+type SampledOut struct {
+	PC uintptr
+}
+
+// frame returns the frame of the call site.
+func (so SampledOut) frame() runtime.Frame {
+	f, _ := runtime.CallersFrames([]uintptr{so.PC}).Next()
+
+	return f
+}
+
+// String returns a marker with the call site.
+func (so SampledOut) String() string {
+	f := so.frame()
+
+	return fmt.Sprintf("trace sampled out at %s\n    %s:%d", f.Function, FramePath(f), f.Line)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (so SampledOut) MarshalJSON() ([]byte, error) {
+	f := so.frame()
+
+	output := struct {
+		SampledOut bool   `json:"sampled_out"`
+		Function   string `json:"function"`
+		File       string `json:"file"`
+		Line       int    `json:"line"`
+	}{
+		SampledOut: true,
+		Function:   f.Function,
+		File:       FramePath(f),
+		Line:       f.Line,
+	}
+
+	return json.Marshal(output)
+}
+
+// sampledCapturer skips the captures that aren't sampled.
+type sampledCapturer struct {
+	capturer Capturer
+	sampler  Sampler
+}
+
+// CaptureSampled returns a Capturer that only uses c for the captures taken by
+// the sampler. Skipped captures record SampledOut instead. If c is nil, then
+// CaptureFrames is used.
+//
+//	oops.SetDefaultCapturer(oops.CaptureSampled(nil, oops.SampleCallSite(1, 10)))
+func CaptureSampled(c Capturer, s Sampler) Capturer {
+	if c == nil {
+		c = CaptureFunc[Frames](CaptureFrames)
+	}
+
+	return &sampledCapturer{
+		capturer: c,
+		sampler:  s,
+	}
+}
+
+// Capture implements Capturer.
+func (sc *sampledCapturer) Capture(err error, skip int) any {
+	return sc.capture(nil, err, skip+1)
+}
+
+// CaptureContext implements ContextCapturer. The context is given to the
+// wrapped capturer if it is a ContextCapturer.
+func (sc *sampledCapturer) CaptureContext(ctx context.Context, err error, skip int) any {
+	return sc.capture(ctx, err, skip+1)
+}
+
+// capture uses the wrapped capturer with ctx if the call site is sampled.
+func (sc *sampledCapturer) capture(ctx context.Context, err error, skip int) any {
+	// The call site is 3 frames closer than for a direct capture which also
+	// has the callers, CaptureRuntimeFrames, and CaptureFrames frames.
+	// Helpers are skipped so that their callers are sampled separately.
+	pc := callSite(skip - 2)
+
+	if !sc.sampler.Sample(pc) {
+		return SampledOut{
+			PC: pc,
+		}
+	}
+
+	if cc, ok := sc.capturer.(ContextCapturer); ok && ctx != nil {
+		return cc.CaptureContext(ctx, err, skip+1)
+	}
+
+	return sc.capturer.Capture(err, skip+1)
+}
@@ -0,0 +1,123 @@
+package oops_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func samples(s oops.Sampler, pcs ...uintptr) []bool {
+	output := make([]bool, 0, len(pcs))
+	for _, pc := range pcs {
+		output = append(output, s.Sample(pc))
+	}
+
+	return output
+}
+
+func TestSamplers(t *testing.T) {
+	t.Run("SampleEvery", func(t *testing.T) {
+		require.Equal(t, []bool{true, false, false, true, false, false, true}, samples(oops.SampleEvery(3), 1, 1, 1, 1, 1, 1, 1))
+		require.Equal(t, []bool{true, true}, samples(oops.SampleEvery(0), 1, 1))
+	})
+
+	t.Run("SampleCallSite", func(t *testing.T) {
+		require.Equal(t, []bool{true, true, true, false, true, false}, samples(oops.SampleCallSite(0.001, 2), 1, 2, 1, 1, 2, 2))
+	})
+
+	t.Run("SampleWindow", func(t *testing.T) {
+		require.Equal(t, []bool{true, true, false, false}, samples(oops.SampleWindow(time.Hour, 2), 1, 2, 3, 4))
+
+		s := oops.SampleWindow(time.Nanosecond, 1)
+		require.True(t, s.Sample(1))
+		time.Sleep(time.Millisecond)
+		require.True(t, s.Sample(1))
+	})
+}
+
+func sampledTrace(c oops.Capturer) error {
+	return oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: c,
+	})
+}
+
+func TestCaptureSampled(t *testing.T) {
+	pcs := []uintptr{}
+	c := oops.CaptureSampled(nil, oops.SamplerFunc(func(pc uintptr) bool {
+		pcs = append(pcs, pc)
+
+		return len(pcs) == 1
+	}))
+
+	errs := []error{}
+	for i := 0; i < 2; i++ {
+		errs = append(errs, sampledTrace(c))
+	}
+
+	require.Len(t, pcs, 2)
+	require.Equal(t, pcs[0], pcs[1])
+
+	fs := errs[0].(*oops.TraceError).Frames()
+	require.Equal(t, "github.com/calebcase/oops_test.sampledTrace", fs[0].Function)
+
+	so, ok := errs[1].(*oops.TraceError).Data.(oops.SampledOut)
+	require.True(t, ok)
+	require.Equal(t, pcs[1], so.PC)
+
+	output := fmt.Sprintf("%+v", errs[1])
+	require.True(t, strings.HasPrefix(output, "bad stuff\n··trace sampled out at github.com/calebcase/oops_test.sampledTrace\n··    "), output)
+
+	bs, err := json.Marshal(errs[1])
+	require.NoError(t, err)
+
+	var decoded struct {
+		Data struct {
+			SampledOut bool   `json:"sampled_out"`
+			Function   string `json:"function"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(bs, &decoded))
+	require.True(t, decoded.Data.SampledOut)
+	require.Equal(t, "github.com/calebcase/oops_test.sampledTrace", decoded.Data.Function)
+
+	t.Run("default", func(t *testing.T) {
+		oops.SetDefaultCapturer(oops.CaptureSampled(oops.CaptureFunc[*oops.LazyFrames](oops.CaptureLazyFrames), oops.SampleEvery(2)))
+		defer oops.SetDefaultCapturer(oops.CaptureFunc[oops.Frames](oops.CaptureFrames))
+
+		first := oops.New("bad stuff")
+		second := oops.New("bad stuff")
+
+		fs := first.(*oops.TraceError).Frames()
+		require.Equal(t, "github.com/calebcase/oops_test.TestCaptureSampled.func2", fs[0].Function)
+
+		output := fmt.Sprintf("%+v", second)
+		require.Contains(t, output, "trace sampled out at github.com/calebcase/oops_test.TestCaptureSampled.func2")
+	})
+
+	t.Run("ContextCapturer", func(t *testing.T) {
+		c := oops.CaptureSampled(oops.CaptureGoroutine(), oops.SampleEvery(1))
+
+		var err error
+		pprof.Do(context.Background(), pprof.Labels("task", "abc"), func(ctx context.Context) {
+			err = oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+				Skip:     oops.TraceSkipInternal - 1,
+				Capturer: c,
+				Context:  ctx,
+			})
+		})
+
+		gf, ok := err.(*oops.TraceError).Data.(oops.GoroutineFrames)
+		require.True(t, ok)
+		require.Equal(t, "github.com/calebcase/oops_test.TestCaptureSampled.func3.1", gf.Frames[0].Function)
+		require.Equal(t, map[string]string{"task": "abc"}, gf.Labels)
+	})
+}
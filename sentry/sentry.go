@@ -0,0 +1,323 @@
+// Package sentry encodes oops errors as Sentry event payloads.
+//
+// The event is plain JSON and doesn't depend on the Sentry SDK. Every link of
+// an oops.ChainError and every wrapped cause becomes an exception entry
+// (linked together as an exception group), oops.TraceError frames become
+// stacktrace frames, namespaces and codes become tags, and fields become extra
+// data.
+//
+// The event ID and timestamp are left for the caller (or the SDK) to fill in.
+package sentry
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/calebcase/oops"
+)
+
+// Event is a Sentry event.
+type Event struct {
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Exception   Exceptions        `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       oops.Fields       `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+}
+
+// Exceptions is the exception interface of an event.
+type Exceptions struct {
+	// Values are ordered from the innermost cause to the outermost error.
+	Values []Exception `json:"values"`
+}
+
+// Exception is a single exception of an event.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+	Mechanism  *Mechanism  `json:"mechanism,omitempty"`
+}
+
+// Mechanism describes how an exception relates to the others.
+type Mechanism struct {
+	Type             string `json:"type"`
+	Source           string `json:"source,omitempty"`
+	ExceptionID      int    `json:"exception_id"`
+	ParentID         *int   `json:"parent_id,omitempty"`
+	IsExceptionGroup bool   `json:"is_exception_group,omitempty"`
+}
+
+// Stacktrace is the stacktrace of an exception.
+type Stacktrace struct {
+	// Frames are ordered from the outermost call to the innermost call.
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a single frame of a stacktrace.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+// Encoder encodes errors as events.
+type Encoder struct {
+	// InApp reports whether the frame is part of the application. If nil,
+	// then frames from the main module are in the app (see InAppModules).
+	InApp func(f runtime.Frame) bool
+}
+
+// Default is the encoder used by the package level functions.
+var Default = &Encoder{}
+
+// Encode returns the event for err. If err is nil, then nil is returned.
+func (e *Encoder) Encode(err error) *Event {
+	if err == nil {
+		return nil
+	}
+
+	inApp := e.InApp
+	if inApp == nil {
+		inApp = defaultInApp
+	}
+
+	enc := &encoder{
+		inApp: inApp,
+		tags:  map[string]string{},
+	}
+	enc.exception(err, nil, "")
+
+	// Sentry expects the outermost exception last.
+	values := enc.values
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+
+	event := &Event{
+		Level:    "error",
+		Platform: "go",
+		Exception: Exceptions{
+			Values: values,
+		},
+		Extra:       oops.FieldsOf(err),
+		Fingerprint: []string{oops.Fingerprint(err)},
+	}
+
+	if len(enc.tags) > 0 {
+		event.Tags = enc.tags
+	}
+
+	return event
+}
+
+// Encode returns the event for err using Default.
+func Encode(err error) *Event {
+	return Default.Encode(err)
+}
+
+// InAppModules returns an InApp function that reports frames from packages
+// in the given modules as in the app.
+func InAppModules(modules ...string) func(f runtime.Frame) bool {
+	return func(f runtime.Frame) bool {
+		pkg := strings.TrimSuffix(oops.FramePackage(f), "_test")
+
+		for _, m := range modules {
+			if pkg == m || strings.HasPrefix(pkg, m+"/") {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// defaultInApp reports frames from the main module (or the main package) as
+// in the app.
+var defaultInApp = func() func(f runtime.Frame) bool {
+	modules := []string{"main"}
+
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Path != "" {
+		modules = append(modules, bi.Main.Path)
+	}
+
+	return InAppModules(modules...)
+}()
+
+// encoder holds the state while encoding a single event.
+type encoder struct {
+	inApp  func(f runtime.Frame) bool
+	values []Exception
+	tags   map[string]string
+}
+
+// exception adds the exception for err and its causes. The wrappers that
+// decorate an error (traces, namespaces, classes, fields, and shadows) are
+// folded into the exception of the error they wrap.
+func (enc *encoder) exception(err error, parent *int, source string) {
+	var frames oops.Frames
+	var module string
+	var hidden []error
+
+	policy := oops.GetRedactPolicy()
+
+	for {
+		switch e := err.(type) {
+		case *oops.TraceError:
+			if fs := e.Frames(); len(fs) > 0 && policy.Frames == oops.RedactRender {
+				frames = fs
+			}
+
+			err = e.Err
+
+			continue
+		case *oops.NamespaceError:
+			enc.tag("namespace", e.Name)
+
+			if module == "" {
+				module = e.Name
+			}
+
+			err = e.Err
+
+			continue
+		case *oops.ClassError:
+			if e.Class.Namespace != "" {
+				enc.tag("namespace", string(e.Class.Namespace))
+			}
+
+			enc.tag("code", string(e.Class.Code))
+
+			err = e.Err
+
+			continue
+		case *oops.FieldsError:
+			err = e.Err
+
+			continue
+		case *oops.ShadowError:
+			if e.Hidden != nil && policy.Hidden != oops.RedactDrop {
+				hidden = append(hidden, e.Hidden)
+			}
+
+			err = e.Err
+
+			continue
+		}
+
+		break
+	}
+
+	if err == nil {
+		return
+	}
+
+	id := len(enc.values)
+	children := oops.UnwrapAll(err)
+
+	mechanism := &Mechanism{
+		Type:        "generic",
+		Source:      source,
+		ExceptionID: id,
+		ParentID:    parent,
+	}
+
+	if parent != nil {
+		mechanism.Type = "chained"
+	}
+
+	var sourceOf func(i int) string
+
+	switch err.(type) {
+	case oops.ChainError:
+		mechanism.IsExceptionGroup = true
+		sourceOf = func(i int) string {
+			return fmt.Sprintf("links[%d]", i)
+		}
+	case interface{ Unwrap() []error }:
+		mechanism.IsExceptionGroup = true
+		sourceOf = func(i int) string {
+			return fmt.Sprintf("errors[%d]", i)
+		}
+	default:
+		sourceOf = func(int) string {
+			return "cause"
+		}
+	}
+
+	enc.values = append(enc.values, Exception{
+		Type:       oops.TypeName(err),
+		Value:      err.Error(),
+		Module:     module,
+		Stacktrace: enc.stacktrace(frames),
+		Mechanism:  mechanism,
+	})
+
+	for _, h := range hidden {
+		if policy.Hidden == oops.RedactMask {
+			enc.values = append(enc.values, Exception{
+				Type:  "hidden",
+				Value: oops.Redacted,
+				Mechanism: &Mechanism{
+					Type:        "chained",
+					Source:      "hidden",
+					ExceptionID: len(enc.values),
+					ParentID:    &id,
+				},
+			})
+
+			continue
+		}
+
+		enc.exception(h, &id, "hidden")
+	}
+
+	for i, child := range children {
+		if child == nil {
+			continue
+		}
+
+		enc.exception(child, &id, sourceOf(i))
+	}
+}
+
+// tag sets the tag if it isn't already set. The outermost value wins.
+func (enc *encoder) tag(key, value string) {
+	if _, ok := enc.tags[key]; ok || value == "" {
+		return
+	}
+
+	enc.tags[key] = value
+}
+
+// stacktrace returns the stacktrace for the frames.
+func (enc *encoder) stacktrace(fs oops.Frames) *Stacktrace {
+	if len(fs) == 0 {
+		return nil
+	}
+
+	st := &Stacktrace{
+		Frames: make([]Frame, 0, len(fs)),
+	}
+
+	for i := len(fs) - 1; i >= 0; i-- {
+		f := fs[i]
+		pkg := oops.FramePackage(f)
+
+		st.Frames = append(st.Frames, Frame{
+			Function: strings.TrimPrefix(strings.TrimPrefix(f.Function, pkg), "."),
+			Module:   pkg,
+			Filename: oops.FramePath(f),
+			Lineno:   f.Line,
+			InApp:    enc.inApp(f),
+		})
+	}
+
+	return st
+}
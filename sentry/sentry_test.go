@@ -0,0 +1,156 @@
+package sentry_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/calebcase/oops/sentry"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var (
+	Error = oops.Namespace("sentry_test")
+
+	ErrNotFound = Error.Class(oops.CodeNotFound)
+)
+
+// frames returns frames for the functions with predictable files and lines.
+func frames(functions ...string) oops.Frames {
+	fs := oops.Frames{}
+	for i, fn := range functions {
+		slash := strings.LastIndex(fn, "/") + 1
+		dir := fn[:slash+strings.Index(fn[slash:], ".")]
+		fs = append(fs, runtime.Frame{
+			Function: fn,
+			File:     "/build/" + dir + "/file.go",
+			Line:     10 * (i + 1),
+		})
+	}
+
+	return fs
+}
+
+// trace returns err traced with the frames.
+func trace(err error, functions ...string) error {
+	return &oops.TraceError{
+		Data: frames(functions...),
+		Err:  err,
+	}
+}
+
+func golden(t *testing.T, name string, event *sentry.Event) {
+	t.Helper()
+
+	bs, err := json.MarshalIndent(event, "", "  ")
+	require.NoError(t, err)
+	bs = append(bs, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		require.NoError(t, os.WriteFile(path, bs, 0o644))
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(bs))
+}
+
+func TestEncode(t *testing.T) {
+	type TC struct {
+		Name string
+		Err  error
+	}
+
+	tcs := []TC{
+		{
+			Name: "trace",
+			Err: oops.With(
+				ErrNotFound.Wrap(trace(
+					errors.New("thing not found"),
+					"github.com/calebcase/oops/sentry_test.lookup",
+					"github.com/calebcase/oops/sentry_test.handler",
+					"net/http.HandlerFunc.ServeHTTP",
+				)),
+				"id", 1,
+				"user", "alice",
+			),
+		},
+		{
+			Name: "chain",
+			Err: Error.Wrap(oops.Chain(
+				trace(
+					errors.New("write failed"),
+					"github.com/calebcase/oops/sentry_test.write",
+					"main.main",
+				),
+				trace(
+					fmt.Errorf("close: %w", io.ErrClosedPipe),
+					"github.com/calebcase/oops/sentry_test.close",
+					"main.main",
+				),
+			)),
+		},
+		{
+			Name: "shadow",
+			Err: oops.Shadow(
+				trace(errors.New("db password rejected"), "github.com/lib/pq.(*conn).auth", "main.main"),
+				ErrNotFound.Wrap(errors.New("thing not found")),
+			),
+		},
+		{
+			Name: "join",
+			Err:  errors.Join(io.EOF, io.ErrUnexpectedEOF),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			golden(t, tc.Name, sentry.Encode(tc.Err))
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		require.Nil(t, sentry.Encode(nil))
+	})
+
+	t.Run("decoded", func(t *testing.T) {
+		bs, err := oops.MarshalError(tcs[0].Err)
+		require.NoError(t, err)
+
+		derr, err := oops.UnmarshalError(bs)
+		require.NoError(t, err)
+
+		golden(t, "trace", sentry.Encode(derr))
+	})
+
+	t.Run("paths", func(t *testing.T) {
+		oops.SetPathRewriters(oops.ReplacePrefix("/build/", ""))
+		defer oops.SetPathRewriters()
+
+		bs, err := json.Marshal(sentry.Encode(tcs[0].Err))
+		require.NoError(t, err)
+		require.Contains(t, string(bs), `"filename":"net/http/file.go"`)
+		require.NotContains(t, string(bs), "/build/")
+	})
+}
+
+func TestInAppModules(t *testing.T) {
+	inApp := sentry.InAppModules("github.com/you/app")
+
+	require.True(t, inApp(runtime.Frame{Function: "github.com/you/app.Run"}))
+	require.True(t, inApp(runtime.Frame{Function: "github.com/you/app/store.(*DB).Get"}))
+	require.False(t, inApp(runtime.Frame{Function: "github.com/you/application.Run"}))
+	require.False(t, inApp(runtime.Frame{Function: "net/http.HandlerFunc.ServeHTTP"}))
+}
@@ -3,6 +3,7 @@ package oops
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/calebcase/oops/lines"
@@ -31,8 +32,14 @@ func (se *ShadowError) Unwrap() error {
 	return se.Err
 }
 
-// Format implements fmt.Format.
+// Format implements fmt.Format. With %+v the hidden error is included
+// according to the redaction policy.
 func (se *ShadowError) Format(f fmt.State, verb rune) {
+	se.formatRelative(f, verb, nil, redactPolicy.Load())
+}
+
+// formatRelative implements relativeFormatter.
+func (se *ShadowError) formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy) {
 	if se == nil || se.Err == nil || se.Hidden == nil {
 		fmt.Fprintf(f, "<nil>")
 
@@ -50,25 +57,36 @@ func (se *ShadowError) Format(f fmt.State, verb rune) {
 		return
 	}
 
-	output := lines.Indent(lines.Sprintf("%"+flag+string(verb), se.Err), "··", 1)
-	hidden := lines.Indent(lines.Sprintf("%"+flag+string(verb), se.Hidden), "··", 1)
+	output := lines.Indent(lines.Sprintf("%"+flag+string(verb), relativeTo{se.Err, ref, policy}), "··", 1)
+
+	switch policy.Hidden {
+	case RedactRender:
+		hidden := lines.Indent(lines.Sprintf("%"+flag+string(verb), relativeTo{se.Hidden, nil, policy}), "··", 1)
 
-	output = append(output, "··hidden: "+hidden[0])
+		output = append(output, "··hidden: "+hidden[0])
 
-	if len(hidden) > 1 {
-		output = append(output, hidden[1:]...)
+		if len(hidden) > 1 {
+			output = append(output, hidden[1:]...)
+		}
+	case RedactMask:
+		output = append(output, "··hidden: "+Redacted)
 	}
 
-	fmt.Fprintf(f, strings.Join(output, "\n"))
+	io.WriteString(f, strings.Join(output, "\n"))
 }
 
-// MarshalJSON implements json.Marshaler.
+// MarshalJSON implements json.Marshaler. The hidden error is never included.
 func (se *ShadowError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(se)
+}
+
+// marshalJSON implements policyMarshaler.
+func (se *ShadowError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
 	if se == nil || se.Err == nil {
 		return []byte("null"), nil
 	}
 
-	ebs, err := ErrorMarshalJSON(se.Err)
+	ebs, err := errorMarshalJSON(se.Err, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +95,7 @@ func (se *ShadowError) MarshalJSON() (bs []byte, err error) {
 		Type string          `json:"type"`
 		Err  json.RawMessage `json:"err"`
 	}{
-		Type: fmt.Sprintf("%T", se.Err),
+		Type: TypeName(se.Err),
 		Err:  json.RawMessage(ebs),
 	}
 
@@ -0,0 +1,291 @@
+package oops
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+var (
+	_ slog.LogValuer = &TraceError{}
+	_ slog.LogValuer = &NamespaceError{}
+	_ slog.LogValuer = &ShadowError{}
+	_ slog.LogValuer = ChainError{}
+	_ slog.LogValuer = &FieldsError{}
+	_ slog.LogValuer = &VerboseError{}
+	_ slog.LogValuer = &ClassError{}
+)
+
+// SlogOptions controls how errors are converted to slog values.
+type SlogOptions struct {
+	// Hidden includes the hidden error of ShadowErrors.
+	Hidden bool
+
+	// Policy is the redaction policy to use. If nil, then the package level
+	// policy is used.
+	Policy *RedactPolicy
+}
+
+// policy returns the redaction policy to use.
+func (opts SlogOptions) policy() *RedactPolicy {
+	if opts.Policy != nil {
+		return opts.Policy.resolve()
+	}
+
+	return redactPolicy.Load()
+}
+
+// SlogValue returns err as a slog.Value. Oops errors are returned as groups
+// of attributes:
+//
+//   - TraceError: err and frames (or data if the data is MultiData or has no
+//     frames)
+//   - NamespaceError: namespace and err
+//   - ShadowError: err and hidden (only if opts.Hidden is set)
+//   - ChainError: one attribute per link keyed by its index
+//   - FieldsError: err and fields
+//   - ClassError: code and err
+//
+// Other errors are returned as their Error() string. Hidden errors, trace
+// data, and fields are included according to the redaction policy (the policy
+// of a RedactError replaces opts.Policy for the error it wraps).
+func SlogValue(err error, opts SlogOptions) slog.Value {
+	switch e := err.(type) {
+	case nil:
+		return slog.StringValue("<nil>")
+	case *TraceError:
+		if e == nil || e.Err == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		attrs := []slog.Attr{
+			{Key: "err", Value: SlogValue(e.Err, opts)},
+		}
+
+		switch opts.policy().Frames {
+		case RedactRender:
+			if md, ok := e.Data.(MultiData); ok {
+				attrs = append(attrs, slog.Attr{Key: "data", Value: md.LogValue()})
+			} else if fs := e.Frames(); fs != nil {
+				attrs = append(attrs, slog.Attr{Key: "frames", Value: fs.LogValue()})
+			} else if e.Data != nil {
+				attrs = append(attrs, slog.Any("data", e.Data))
+			}
+		case RedactMask:
+			attrs = append(attrs, slog.String("data", Redacted))
+		}
+
+		return slog.GroupValue(attrs...)
+	case *NamespaceError:
+		if e == nil || e.Err == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		return slog.GroupValue(
+			slog.String("namespace", e.Name),
+			slog.Attr{Key: "err", Value: SlogValue(e.Err, opts)},
+		)
+	case *ShadowError:
+		if e == nil || e.Err == nil || e.Hidden == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		attrs := []slog.Attr{
+			{Key: "err", Value: SlogValue(e.Err, opts)},
+		}
+
+		if opts.Hidden {
+			switch opts.policy().Hidden {
+			case RedactRender:
+				attrs = append(attrs, slog.Attr{Key: "hidden", Value: SlogValue(e.Hidden, opts)})
+			case RedactMask:
+				attrs = append(attrs, slog.String("hidden", Redacted))
+			}
+		}
+
+		return slog.GroupValue(attrs...)
+	case ChainError:
+		if len(e) == 0 {
+			return slog.StringValue("<nil>")
+		}
+
+		attrs := make([]slog.Attr, 0, len(e))
+		for i, link := range e {
+			attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: SlogValue(link, opts)})
+		}
+
+		return slog.GroupValue(attrs...)
+	case *FieldsError:
+		if e == nil || e.Err == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		policy := opts.policy()
+
+		fields := make([]slog.Attr, 0, len(e.Fields))
+		for _, f := range e.Fields {
+			switch policy.field(f.Key) {
+			case RedactRender:
+				fields = append(fields, slog.Any(f.Key, redactValue(f.Value, policy)))
+			case RedactMask:
+				fields = append(fields, slog.String(f.Key, Redacted))
+			}
+		}
+
+		return slog.GroupValue(
+			slog.Attr{Key: "err", Value: SlogValue(e.Err, opts)},
+			slog.Attr{Key: "fields", Value: slog.GroupValue(fields...)},
+		)
+	case *ClassError:
+		if e == nil || e.Err == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		return slog.GroupValue(
+			slog.String("code", string(e.Class.Code)),
+			slog.Attr{Key: "err", Value: SlogValue(e.Err, opts)},
+		)
+	case *VerboseError:
+		if e == nil || e.Err == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		return SlogValue(e.Err, opts)
+	case *RedactError:
+		if e == nil || e.Err == nil {
+			return slog.StringValue("<nil>")
+		}
+
+		opts.Policy = &e.Policy
+
+		return SlogValue(e.Err, opts)
+	}
+
+	return slog.StringValue(err.Error())
+}
+
+// LogValue implements slog.LogValuer. Each frame is an attribute keyed by its
+// index.
+func (fs Frames) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(fs))
+	for i, f := range fs {
+		attrs = append(attrs, slog.String(strconv.Itoa(i), fmt.Sprintf("%s %s:%d", f.Function, FramePath(f), f.Line)))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer.
+func (te *TraceError) LogValue() slog.Value {
+	return SlogValue(te, SlogOptions{})
+}
+
+// LogValue implements slog.LogValuer.
+func (ne *NamespaceError) LogValue() slog.Value {
+	return SlogValue(ne, SlogOptions{})
+}
+
+// LogValue implements slog.LogValuer. The hidden error is not included.
+func (se *ShadowError) LogValue() slog.Value {
+	return SlogValue(se, SlogOptions{})
+}
+
+// LogValue implements slog.LogValuer.
+func (ce ChainError) LogValue() slog.Value {
+	return SlogValue(ce, SlogOptions{})
+}
+
+// LogValue implements slog.LogValuer.
+func (fe *FieldsError) LogValue() slog.Value {
+	return SlogValue(fe, SlogOptions{})
+}
+
+// LogValue implements slog.LogValuer.
+func (ve *VerboseError) LogValue() slog.Value {
+	return SlogValue(ve, SlogOptions{})
+}
+
+// LogValue implements slog.LogValuer.
+func (ce *ClassError) LogValue() slog.Value {
+	return SlogValue(ce, SlogOptions{})
+}
+
+// SlogHandler is a slog.Handler middleware that expands error attributes with
+// SlogValue before passing them on.
+type SlogHandler struct {
+	handler slog.Handler
+	opts    SlogOptions
+}
+
+var _ slog.Handler = &SlogHandler{}
+
+// NewSlogHandler returns a handler that expands error attributes and passes
+// the records to h.
+func NewSlogHandler(h slog.Handler, opts SlogOptions) *SlogHandler {
+	return &SlogHandler{
+		handler: h,
+		opts:    opts,
+	}
+}
+
+// Enabled implements slog.Handler.
+func (sh *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return sh.handler.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (sh *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	expanded := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		expanded.AddAttrs(sh.expand(a))
+
+		return true
+	})
+
+	return sh.handler.Handle(ctx, expanded)
+}
+
+// WithAttrs implements slog.Handler.
+func (sh *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		expanded = append(expanded, sh.expand(a))
+	}
+
+	return &SlogHandler{
+		handler: sh.handler.WithAttrs(expanded),
+		opts:    sh.opts,
+	}
+}
+
+// WithGroup implements slog.Handler.
+func (sh *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{
+		handler: sh.handler.WithGroup(name),
+		opts:    sh.opts,
+	}
+}
+
+// expand replaces error values in a (including those in groups) with their
+// SlogValue.
+func (sh *SlogHandler) expand(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = SlogValue(err, sh.opts)
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			attrs = append(attrs, sh.expand(ga))
+		}
+
+		a.Value = slog.GroupValue(attrs...)
+	}
+
+	return a
+}
@@ -0,0 +1,94 @@
+package oops_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func logJSON(t *testing.T, h func(*bytes.Buffer) slog.Handler, args ...any) map[string]any {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	slog.New(h(buf)).Error("failed", args...)
+	t.Log(buf.String())
+
+	output := map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+
+	return output
+}
+
+func TestSlog(t *testing.T) {
+	plain := func(buf *bytes.Buffer) slog.Handler {
+		return slog.NewJSONHandler(buf, nil)
+	}
+
+	t.Run("LogValuer", func(t *testing.T) {
+		ns := oops.Namespace("slog")
+		err := ns.Chain(
+			oops.New("first"),
+			oops.Shadow(errors.New("secret"), errors.New("public")),
+		)
+
+		output := logJSON(t, plain, "err", err)
+
+		e := output["err"].(map[string]any)
+		require.Equal(t, "slog", e["namespace"])
+
+		links := e["err"].(map[string]any)
+		require.Len(t, links, 2)
+
+		first := links["0"].(map[string]any)
+		require.Equal(t, "first", first["err"])
+		require.Contains(t, first["frames"].(map[string]any)["0"], "TestSlog")
+
+		second := links["1"].(map[string]any)
+		require.Equal(t, "public", second["err"])
+		require.NotContains(t, second, "hidden")
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		err := oops.With(errors.New("bad stuff"), "user_id", 42)
+
+		output := logJSON(t, plain, "err", err)
+
+		e := output["err"].(map[string]any)
+		require.Equal(t, "bad stuff", e["err"])
+		require.Equal(t, map[string]any{"user_id": float64(42)}, e["fields"])
+	})
+
+	t.Run("SlogHandler", func(t *testing.T) {
+		hidden := func(buf *bytes.Buffer) slog.Handler {
+			return oops.NewSlogHandler(slog.NewJSONHandler(buf, nil), oops.SlogOptions{Hidden: true})
+		}
+
+		err := oops.Shadow(errors.New("secret"), errors.New("public"))
+
+		output := logJSON(t, hidden, "err", err, slog.Group("g", "err", err), "plain", errors.New("plain"))
+
+		e := output["err"].(map[string]any)
+		require.Equal(t, "public", e["err"])
+		require.Equal(t, "secret", e["hidden"])
+
+		g := output["g"].(map[string]any)["err"].(map[string]any)
+		require.Equal(t, "secret", g["hidden"])
+
+		require.Equal(t, "plain", output["plain"])
+
+		buf := &bytes.Buffer{}
+		logger := slog.New(hidden(buf)).With("err", err).WithGroup("sub")
+		logger.Info("msg", "x", 1)
+		t.Log(buf.String())
+
+		output = map[string]any{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &output))
+		require.Equal(t, "secret", output["err"].(map[string]any)["hidden"])
+		require.Equal(t, float64(1), output["sub"].(map[string]any)["x"])
+	})
+}
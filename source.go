@@ -0,0 +1,128 @@
+package oops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/calebcase/oops/lines"
+)
+
+// SourceFrames are frames that are formatted with the lines of source code
+// around each frame. The source is only included if the file can be read
+// (e.g. when running on the machine that built the binary).
+type SourceFrames struct {
+	Frames Frames
+
+	// Context is the number of lines to include before and after the line
+	// of each frame.
+	Context int
+}
+
+// String returns the frames formatted by Frames.String with the source code
+// context below each frame. The line of the frame is marked with a caret.
+func (sf SourceFrames) String() string {
+	ls := []string{}
+
+	for i, f := range sf.Frames {
+		prefix := fmt.Sprintf("[%d] ", i)
+		indent := strings.Repeat(" ", len(prefix))
+		ls = append(ls, prefix+f.Function)
+		ls = append(ls, indent+fmt.Sprintf("%s:%d", FramePath(f), f.Line))
+		ls = append(ls, lines.Indent(sourceContext(f.File, f.Line, sf.Context), indent+"  ", 0)...)
+	}
+
+	return strings.Join(ls, "\n")
+}
+
+// TraceFrames implements Framer.
+func (sf SourceFrames) TraceFrames() Frames {
+	return sf.Frames
+}
+
+// FilterFrames implements Filterer.
+func (sf SourceFrames) FilterFrames(filters ...FrameFilter) any {
+	sf.Frames = sf.Frames.Filter(filters...)
+
+	return sf
+}
+
+// MarshalJSON implements json.Marshaler. The source code is not included.
+func (sf SourceFrames) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sf.Frames)
+}
+
+// CaptureSource returns a Capturer that captures the stack as SourceFrames
+// with n lines of context.
+func CaptureSource(n int) Capturer {
+	return CaptureFunc[SourceFrames](func(err error, skip int) SourceFrames {
+		return SourceFrames{
+			Frames:  CaptureFrames(err, skip+1),
+			Context: n,
+		}
+	})
+}
+
+// sourceContext returns the numbered lines of file around line. If the file
+// can't be read or doesn't have the line, then nil is returned.
+func sourceContext(file string, line, n int) []string {
+	src := sourceLines(file)
+	if line < 1 || line > len(src) {
+		return nil
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	start := max(line-n, 1)
+	end := min(line+n, len(src))
+	width := len(strconv.Itoa(end))
+
+	output := []string{}
+	for l := start; l <= end; l++ {
+		text := src[l-1]
+		output = append(output, strings.TrimRight(fmt.Sprintf("%*d | %s", width, l, text), " \t"))
+
+		if l == line {
+			ws := text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+			output = append(output, strings.Repeat(" ", width)+" | "+ws+"^")
+		}
+	}
+
+	return output
+}
+
+// sourceCache holds the lines of the files read by sourceLines. Files that
+// couldn't be read are cached as nil.
+var sourceCache = struct {
+	sync.Mutex
+	files map[string][]string
+}{
+	files: map[string][]string{},
+}
+
+// sourceLines returns the lines of file. The lines are cached so each file is
+// only read once.
+func sourceLines(file string) []string {
+	sourceCache.Lock()
+	defer sourceCache.Unlock()
+
+	if ls, ok := sourceCache.files[file]; ok {
+		return ls
+	}
+
+	var ls []string
+
+	bs, err := os.ReadFile(file)
+	if err == nil {
+		ls = strings.Split(strings.ReplaceAll(string(bs), "\r\n", "\n"), "\n")
+	}
+
+	sourceCache.files[file] = ls
+
+	return ls
+}
@@ -0,0 +1,63 @@
+package oops_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+func sourceTrace() error {
+	return oops.TraceWithOptions(errors.New("bad stuff"), oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: oops.CaptureSource(1),
+	})
+}
+
+func TestSourceFrames(t *testing.T) {
+	err := sourceTrace()
+
+	te := err.(*oops.TraceError)
+	sf, ok := te.Data.(oops.SourceFrames)
+	require.True(t, ok)
+	require.Equal(t, "github.com/calebcase/oops_test.sourceTrace", sf.Frames[0].Function)
+
+	ls := strings.Split(sf.String(), "\n")
+	line := sf.Frames[0].Line
+	require.Equal(t, []string{
+		"[0] github.com/calebcase/oops_test.sourceTrace",
+		fmt.Sprintf("    %s:%d", sf.Frames[0].File, line),
+		fmt.Sprintf("      %d | func sourceTrace() error {", line-1),
+		fmt.Sprintf("      %d | \treturn oops.TraceWithOptions(errors.New(\"bad stuff\"), oops.TraceOptions{", line),
+		"         | \t^",
+		fmt.Sprintf("      %d | \t\tSkip:     oops.TraceSkipInternal - 1,", line+1),
+		"[1] github.com/calebcase/oops_test.TestSourceFrames",
+	}, ls[:7])
+
+	output := fmt.Sprintf("%+v", err)
+	require.Contains(t, output, "··         | \t^")
+
+	t.Run("missing", func(t *testing.T) {
+		sf := oops.SourceFrames{
+			Frames: oops.Frames{
+				{Function: "main.main", File: "/does/not/exist.go", Line: 10},
+			},
+			Context: 3,
+		}
+
+		require.Equal(t, "[0] main.main\n    /does/not/exist.go:10", sf.String())
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		bs, err := json.Marshal(sf)
+		require.NoError(t, err)
+
+		var fs []map[string]any
+		require.NoError(t, json.Unmarshal(bs, &fs))
+		require.Equal(t, len(sf.Frames), len(fs))
+	})
+}
@@ -1,17 +1,21 @@
 package oops
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/calebcase/oops/lines"
 )
 
 // TraceSkipInternal is the number of frames created by internal oops calls.
 // This is the number of frames to skip if you would like to only include
-// frames up til the site where TraceN is called.
+// frames up til the site where TraceN is called. Functions that wrap Trace can
+// use Helper (or SetHelperPackages) instead of adjusting the skip.
 const TraceSkipInternal = 7
 
 // Capturer provides a method for capturing trace data.
@@ -19,6 +23,34 @@ type Capturer interface {
 	Capture(err error, skip int) (data any)
 }
 
+// ContextCapturer is a Capturer that can also capture from the context given
+// to the trace (e.g. by TraceCtx or TraceOptions.Context).
+type ContextCapturer interface {
+	Capturer
+
+	CaptureContext(ctx context.Context, err error, skip int) (data any)
+}
+
+// Framer is implemented by trace data that has frames (e.g. Frames,
+// LazyFrames, SourceFrames, GoroutineFrames, and MultiData).
+type Framer interface {
+	TraceFrames() Frames
+}
+
+// Filterer is implemented by trace data that can have frame filters applied
+// (e.g. Frames, LazyFrames, SourceFrames, GoroutineFrames, and MultiData).
+// FilterFrames returns the filtered data without modifying the receiver.
+type Filterer interface {
+	FilterFrames(filters ...FrameFilter) any
+}
+
+// TraceMarshaler is implemented by trace data that adds fields to the JSON of
+// its TraceError (e.g. the goroutine of GoroutineFrames). MarshalTrace returns
+// the value to marshal as the data and the extra fields.
+type TraceMarshaler interface {
+	MarshalTrace() (data any, extra Fields)
+}
+
 // CaptureFunc defines a Capturer that calls the given function to generate the
 // capture.
 type CaptureFunc[T any] func(error, int) T
@@ -38,12 +70,38 @@ func (fs Frames) String() string {
 	for i, f := range fs {
 		prefix := fmt.Sprintf("[%d] ", i)
 		ls = append(ls, prefix+f.Function)
-		ls = append(ls, strings.Repeat(" ", len(prefix))+fmt.Sprintf("%s:%d", f.File, f.Line))
+		ls = append(ls, strings.Repeat(" ", len(prefix))+fmt.Sprintf("%s:%d", FramePath(f), f.Line))
 	}
 
 	return strings.Join(ls, "\n")
 }
 
+// TraceFrames implements Framer.
+func (fs Frames) TraceFrames() Frames {
+	return fs
+}
+
+// FilterFrames implements Filterer.
+func (fs Frames) FilterFrames(filters ...FrameFilter) any {
+	return fs.Filter(filters...)
+}
+
+// MarshalJSON implements json.Marshaler. The file paths are rewritten by the
+// package level path rewriters.
+func (fs Frames) MarshalJSON() ([]byte, error) {
+	if fs == nil {
+		return []byte("null"), nil
+	}
+
+	output := make([]runtime.Frame, len(fs))
+	for i, f := range fs {
+		f.File = FramePath(f)
+		output[i] = f
+	}
+
+	return json.Marshal(output)
+}
+
 // CaptureRuntimeFramesChunk is the initial and additional amount of frames
 // gathered in CaptureRuntimeFrames. When there are more than this many frames
 // to capture the frame buffer will be reallocated with this much additional
@@ -52,25 +110,34 @@ var CaptureRuntimeFramesChunk = 64
 
 // CaptureRuntimeFrames returns the captured stack as []runtime.Frame.
 func CaptureRuntimeFrames(_ error, skip int) []runtime.Frame {
+	return runtimeFrames(callers(skip + 1))
+}
+
+// callers returns the program counters of the stack. It must be called
+// directly by the capture function so that skip lines up with
+// TraceSkipInternal.
+func callers(skip int) []uintptr {
 	// Attempt to gather the callers. If it is truncated, then increase the
 	// size of our buffer and try again.
-	callers := make([]uintptr, CaptureRuntimeFramesChunk)
+	pcs := make([]uintptr, CaptureRuntimeFramesChunk)
 
 	var n int
 	for {
-		n = runtime.Callers(skip, callers)
-		if n < len(callers) {
+		n = runtime.Callers(skip, pcs)
+		if n < len(pcs) {
 			break
 		}
-		callers = make([]uintptr, len(callers)+CaptureRuntimeFramesChunk)
+		pcs = make([]uintptr, len(pcs)+CaptureRuntimeFramesChunk)
 	}
 
-	callers = callers[:n]
+	return pcs[:n]
+}
 
-	// Convert the callers to runtime.Frames.
-	cfs := runtime.CallersFrames(callers)
+// runtimeFrames converts the program counters to []runtime.Frame.
+func runtimeFrames(pcs []uintptr) []runtime.Frame {
+	cfs := runtime.CallersFrames(pcs)
 
-	fs := make([]runtime.Frame, 0, n)
+	fs := make([]runtime.Frame, 0, len(pcs))
 	for {
 		f, more := cfs.Next()
 		if !more {
@@ -88,13 +155,84 @@ func CaptureFrames(err error, skip int) (data Frames) {
 	return Frames(CaptureRuntimeFrames(err, skip))
 }
 
+// LazyFrames is a captured stack that is only resolved into Frames the first
+// time they are needed (e.g. when the trace is formatted or marshalled).
+type LazyFrames struct {
+	Callers []uintptr
+
+	filters []FrameFilter
+	once    sync.Once
+	frames  Frames
+}
+
+// Frames returns the resolved frames.
+func (lf *LazyFrames) Frames() Frames {
+	if lf == nil {
+		return nil
+	}
+
+	lf.once.Do(func() {
+		lf.frames = Frames(runtimeFrames(lf.Callers)).Filter(lf.filters...)
+	})
+
+	return lf.frames
+}
+
+// TraceFrames implements Framer.
+func (lf *LazyFrames) TraceFrames() Frames {
+	return lf.Frames()
+}
+
+// FilterFrames implements Filterer. The frames may be shared (e.g. by a
+// custom capturer) so the filters are added to a copy and the frames are
+// still resolved lazily.
+func (lf *LazyFrames) FilterFrames(filters ...FrameFilter) any {
+	if lf == nil {
+		return lf
+	}
+
+	return &LazyFrames{
+		Callers: lf.Callers,
+		filters: append(append([]FrameFilter{}, lf.filters...), filters...),
+	}
+}
+
+// String returns the resolved frames formatted by Frames.String.
+func (lf *LazyFrames) String() string {
+	return lf.Frames().String()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (lf *LazyFrames) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lf.Frames())
+}
+
+// CaptureLazyFrames returns the captured stack as LazyFrames. Only the program
+// counters are recorded which is much cheaper than CaptureFrames when the
+// trace is never printed.
+func CaptureLazyFrames(_ error, skip int) (data *LazyFrames) {
+	return &LazyFrames{
+		Callers: callers(skip),
+	}
+}
+
+// framesOf returns the frames in the trace data if it is a Framer.
+func framesOf(data any) Frames {
+	if f, ok := data.(Framer); ok {
+		return f.TraceFrames()
+	}
+
+	return nil
+}
+
 // defaultCapturer is the package level setting for the capture function. This
 // is what will be used if no trace options are provided.
 var defaultCapturer Capturer = CaptureFunc[Frames](CaptureFrames)
 
 // SetDefaultCapturer changes the default trace capturer used by calls to
 // Trace, TraceN, and TraceWithOptions. The default capturer creates a capture
-// using CaptureFrames.
+// using CaptureFrames. Use CaptureFunc[*LazyFrames](CaptureLazyFrames) to
+// defer resolving the frames until they are needed.
 func SetDefaultCapturer(c Capturer) {
 	defaultCapturer = c
 }
@@ -119,8 +257,16 @@ func (te *TraceError) Unwrap() error {
 	return te.Err
 }
 
-// Format implements fmt.Format.
+// Format implements fmt.Format. With %+v the frames shared with an inner
+// trace are elided and the data is included according to the redaction
+// policy.
 func (te *TraceError) Format(f fmt.State, verb rune) {
+	te.formatRelative(f, verb, nil, redactPolicy.Load())
+}
+
+// formatRelative implements relativeFormatter. The frames are elided relative
+// to the inner trace if there is one and ref otherwise.
+func (te *TraceError) formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy) {
 	if te == nil || te.Err == nil {
 		fmt.Fprintf(f, "<nil>")
 
@@ -138,20 +284,143 @@ func (te *TraceError) Format(f fmt.State, verb rune) {
 		return
 	}
 
+	// The wrapped error is formatted relative to ref (e.g. a trace in a
+	// chain link relative to the previous link) and this trace's data
+	// relative to the inner trace if there is one.
+	dataRef, label := ref, "previous trace"
+	if inner := innerFrames(te.Err); inner != nil {
+		dataRef, label = inner, "inner trace"
+	}
+
 	output := []string{}
-	output = append(output, lines.Indent(lines.Sprintf("%"+flag+string(verb), te.Err), "··", 1)...)
-	output = append(output, lines.Indent(lines.Sprintf("%"+flag+string(verb), te.Data), "··", 0)...)
+	output = append(output, lines.Indent(lines.Sprintf("%"+flag+string(verb), relativeTo{te.Err, ref, policy}), "··", 1)...)
+
+	switch policy.Frames {
+	case RedactRender:
+		output = append(output, lines.Indent(formatData("%"+flag+string(verb), te.Data, dataRef, label), "··", 0)...)
+	case RedactMask:
+		output = append(output, "··"+Redacted)
+	}
 
 	f.Write([]byte(strings.Join(output, "\n")))
 }
 
+// framesFormatter is implemented by trace data that formats its frames
+// relative to ref itself (e.g. to place other lines after the marker).
+type framesFormatter interface {
+	formatFrames(format string, ref Frames, label string) []string
+}
+
+// formatData returns the trace data formatted as lines. If the data is a
+// Framer and a Filterer, then the frames it has in common with ref are
+// replaced with a marker.
+func formatData(format string, data any, ref Frames, label string) []string {
+	if ff, ok := data.(framesFormatter); ok {
+		return ff.formatFrames(format, ref, label)
+	}
+
+	if _, ok := data.(Filterer); !ok {
+		return lines.Sprintf(format, data)
+	}
+
+	fs := framesOf(data)
+
+	n := commonFrames(fs, ref)
+	if n == len(fs) {
+		// Always keep the top frame.
+		n--
+	}
+
+	if n > 0 {
+		trim := func(fs Frames) Frames {
+			return fs[:len(fs)-n]
+		}
+
+		output := lines.Sprintf("%s", filterData(data, []FrameFilter{trim}))
+		output = append(output, fmt.Sprintf("… %d frames in common with %s", n, label))
+
+		return output
+	}
+
+	return lines.Sprintf(format, data)
+}
+
+// commonFrames returns the number of frames at the bottom of fs that are the
+// same as those at the bottom of ref.
+func commonFrames(fs, ref Frames) (n int) {
+	for n < len(fs) && n < len(ref) {
+		a := fs[len(fs)-1-n]
+		b := ref[len(ref)-1-n]
+
+		if a.Function != b.Function || a.File != b.File || a.Line != b.Line {
+			break
+		}
+
+		n++
+	}
+
+	return n
+}
+
+// innerFrames returns the frames of the first trace found in err's tree.
+func innerFrames(err error) Frames {
+	var te *TraceError
+	if !errors.As(err, &te) {
+		return nil
+	}
+
+	return te.Frames()
+}
+
+// relativeFormatter is implemented by errors that can format their traces
+// relative to the frames of another trace. The redaction policy is passed
+// down to the errors they wrap.
+type relativeFormatter interface {
+	formatRelative(f fmt.State, verb rune, ref Frames, policy *RedactPolicy)
+}
+
+// relativeTo formats err relative to ref with the redaction policy if err
+// supports it.
+type relativeTo struct {
+	err    error
+	ref    Frames
+	policy *RedactPolicy
+}
+
+// Format implements fmt.Format.
+func (rt relativeTo) Format(f fmt.State, verb rune) {
+	if rf, ok := rt.err.(relativeFormatter); ok {
+		rf.formatRelative(f, verb, rt.ref, rt.policy)
+
+		return
+	}
+
+	fmt.Fprintf(f, fmt.FormatString(f, verb), rt.err)
+}
+
+// Frames returns the frames from the trace data if it is a Framer (e.g.
+// Frames, LazyFrames, SourceFrames, GoroutineFrames, or MultiData). If there
+// are no frames, then nil is returned.
+func (te *TraceError) Frames() Frames {
+	if te == nil {
+		return nil
+	}
+
+	return framesOf(te.Data)
+}
+
 // MarshalJSON implements json.Marshaler.
 func (te *TraceError) MarshalJSON() (bs []byte, err error) {
+	return topMarshalJSON(te)
+}
+
+// marshalJSON implements policyMarshaler.
+func (te *TraceError) marshalJSON(policy *RedactPolicy) (bs []byte, err error) {
 	if te == nil || te.Err == nil {
 		return []byte("null"), nil
 	}
 
-	ebs, err := ErrorMarshalJSON(te.Err)
+	ebs, err := errorMarshalJSON(te.Err, policy)
 	if err != nil {
 		return nil, err
 	}
@@ -161,12 +430,34 @@ func (te *TraceError) MarshalJSON() (bs []byte, err error) {
 		Err  json.RawMessage `json:"err"`
 		Data any             `json:"data"`
 	}{
-		Type: fmt.Sprintf("%T", te.Err),
+		Type: TypeName(te.Err),
 		Err:  json.RawMessage(ebs),
-		Data: te.Data,
 	}
 
-	return json.Marshal(output)
+	var extra Fields
+
+	switch policy.Frames {
+	case RedactRender:
+		output.Data = te.Data
+
+		if tm, ok := te.Data.(TraceMarshaler); ok {
+			output.Data, extra = tm.MarshalTrace()
+		}
+	case RedactMask:
+		output.Data = Redacted
+	}
+
+	bs, err = json.Marshal(output)
+	if err != nil || len(extra) == 0 {
+		return bs, err
+	}
+
+	xbs, err := extra.marshalJSON(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinObjects(bs, xbs), nil
 }
 
 // Trace captures a trace and combines it with err. Tracer is use to capture
@@ -191,6 +482,19 @@ type TraceOptions struct {
 	// Capturer controls the specific capturer implementation to use. If
 	// not set, then the package level Capture will be used.
 	Capturer Capturer
+
+	// SkipTraced skips the capture if err already has a TraceError in its
+	// tree. The error is returned as is.
+	SkipTraced bool
+
+	// Context is given to the capturer if it is a ContextCapturer (e.g.
+	// CaptureGoroutine reads the pprof labels from it).
+	Context context.Context
+
+	// Filters are applied to the captured frames (if the capture is a
+	// Filterer, e.g. Frames, LazyFrames, SourceFrames, GoroutineFrames, or
+	// MultiData). If not set, then the package level filters will be used.
+	Filters []FrameFilter
 }
 
 // TraceWithOptions captures a trace using the given options.
@@ -206,12 +510,34 @@ func traceWithOptions(err error, options TraceOptions) error {
 		return nil
 	}
 
+	if options.SkipTraced {
+		var te *TraceError
+		if errors.As(err, &te) {
+			return err
+		}
+	}
+
 	if options.Capturer == nil {
 		options.Capturer = defaultCapturer
 	}
 
+	if options.Filters == nil {
+		options.Filters = getDefaultFilters()
+	}
+
+	var data any
+	if cc, ok := options.Capturer.(ContextCapturer); ok && options.Context != nil {
+		data = cc.CaptureContext(options.Context, err, options.Skip)
+	} else {
+		data = options.Capturer.Capture(err, options.Skip)
+	}
+
+	// Helpers are removed before any other filters (e.g. MaxDepth) are
+	// applied.
+	data = filterData(data, helperFilters)
+
 	return &TraceError{
-		Data: options.Capturer.Capture(err, options.Skip),
+		Data: filterData(data, options.Filters),
 		Err:  err,
 	}
 }
@@ -1,7 +1,10 @@
 package oops_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/calebcase/oops"
@@ -113,3 +116,244 @@ func TestTrace(t *testing.T) {
 		})
 	})
 }
+
+func TestCaptureLazyFrames(t *testing.T) {
+	lazy := oops.CaptureFunc[*oops.LazyFrames](oops.CaptureLazyFrames)
+
+	err := errors.New("bad stuff")
+	eager, lerr := oops.Trace(err), oops.TraceWithOptions(err, oops.TraceOptions{
+		Skip:     oops.TraceSkipInternal - 1,
+		Capturer: lazy,
+	})
+
+	ete := eager.(*oops.TraceError)
+	lte := lerr.(*oops.TraceError)
+
+	lf := lte.Data.(*oops.LazyFrames)
+	require.NotEmpty(t, lf.Callers)
+
+	efs := ete.Frames()
+	lfs := lte.Frames()
+	require.Len(t, lfs, len(efs))
+
+	for i := range efs {
+		require.Equal(t, efs[i].Function, lfs[i].Function)
+		require.Equal(t, efs[i].File, lfs[i].File)
+	}
+
+	require.Equal(t, fmt.Sprintf("%+v", ete.Data), fmt.Sprintf("%+v", lte.Data))
+
+	bs, jerr := json.Marshal(lte.Data)
+	require.NoError(t, jerr)
+
+	var jfs []struct {
+		Function string
+	}
+	require.NoError(t, json.Unmarshal(bs, &jfs))
+	require.Len(t, jfs, len(efs))
+	require.Equal(t, efs[0].Function, jfs[0].Function)
+
+	require.Nil(t, (&oops.TraceError{Data: "custom", Err: err}).Frames())
+}
+
+func BenchmarkTrace(b *testing.B) {
+	err := errors.New("bad stuff")
+
+	capturers := []struct {
+		Name     string
+		Capturer oops.Capturer
+	}{
+		{Name: "CaptureFrames", Capturer: oops.CaptureFunc[oops.Frames](oops.CaptureFrames)},
+		{Name: "CaptureLazyFrames", Capturer: oops.CaptureFunc[*oops.LazyFrames](oops.CaptureLazyFrames)},
+		{Name: "CaptureSampled", Capturer: oops.CaptureSampled(nil, oops.SampleEvery(100))},
+	}
+
+	for _, c := range capturers {
+		options := oops.TraceOptions{
+			Skip:     oops.TraceSkipInternal,
+			Capturer: c.Capturer,
+		}
+
+		b.Run(c.Name, func(b *testing.B) {
+			b.Run("capture", func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					_ = oops.TraceWithOptions(err, options)
+				}
+			})
+
+			b.Run("format", func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					_ = fmt.Sprintf("%+v", oops.TraceWithOptions(err, options))
+				}
+			})
+		})
+	}
+}
+
+func dedupInner() error {
+	return oops.New("inner")
+}
+
+func dedupOuter() error {
+	return oops.Trace(dedupInner())
+}
+
+func TestTraceDedup(t *testing.T) {
+	t.Run("nested", func(t *testing.T) {
+		err := dedupOuter()
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		outer := err.(*oops.TraceError).Frames()
+		require.Contains(t, output, fmt.Sprintf("… %d frames in common with inner trace", len(outer)-1))
+		require.Equal(t, 1, strings.Count(output, "testing.tRunner"))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		ns := oops.Namespace("dedup")
+		err := oops.Chain(dedupInner(), ns.Wrap(dedupInner()), oops.With(dedupInner(), "k", "v"))
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		require.Equal(t, 2, strings.Count(output, "frames in common with previous trace"))
+		require.Equal(t, 1, strings.Count(output, "testing.tRunner"))
+	})
+
+	t.Run("chain nested", func(t *testing.T) {
+		err := oops.Chain(dedupInner(), dedupOuter())
+
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		require.Equal(t, 1, strings.Count(output, "frames in common with previous trace"))
+		require.Equal(t, 1, strings.Count(output, "frames in common with inner trace"))
+		require.Equal(t, 1, strings.Count(output, "testing.tRunner"))
+	})
+
+	t.Run("unrelated", func(t *testing.T) {
+		te := &oops.TraceError{
+			Data: oops.Frames{{Function: "a"}, {Function: "b"}},
+			Err: &oops.TraceError{
+				Data: oops.Frames{{Function: "c"}, {Function: "d"}},
+				Err:  errors.New("bad stuff"),
+			},
+		}
+
+		output := fmt.Sprintf("%+v", te)
+		t.Log(output)
+		require.NotContains(t, output, "in common")
+	})
+
+	t.Run("SkipTraced", func(t *testing.T) {
+		inner := dedupInner()
+
+		err := oops.TraceWithOptions(inner, oops.TraceOptions{
+			SkipTraced: true,
+		})
+		require.Equal(t, inner, err)
+
+		plain := errors.New("plain")
+		err = oops.TraceWithOptions(plain, oops.TraceOptions{
+			SkipTraced: true,
+		})
+		require.NotEqual(t, plain, err)
+		require.ErrorIs(t, err, plain)
+	})
+}
+
+// stepFrames is custom trace data with the step that was running.
+type stepFrames struct {
+	Frames oops.Frames
+	Step   string
+}
+
+func (sf stepFrames) TraceFrames() oops.Frames {
+	return sf.Frames
+}
+
+func (sf stepFrames) FilterFrames(filters ...oops.FrameFilter) any {
+	sf.Frames = sf.Frames.Filter(filters...)
+
+	return sf
+}
+
+func (sf stepFrames) MarshalTrace() (any, oops.Fields) {
+	return sf.Frames, oops.Fields{{Key: "step", Value: sf.Step}}
+}
+
+func (sf stepFrames) String() string {
+	return sf.Frames.String() + "\nstep: " + sf.Step
+}
+
+func decodeStepFrames(fs oops.Frames, extra map[string]json.RawMessage) (any, bool) {
+	bs, ok := extra["step"]
+	if !ok {
+		return nil, false
+	}
+
+	sf := stepFrames{Frames: fs}
+	if err := json.Unmarshal(bs, &sf.Step); err != nil {
+		return nil, false
+	}
+
+	return sf, true
+}
+
+func stepTrace(err error, filters ...oops.FrameFilter) error {
+	return oops.TraceWithOptions(err, oops.TraceOptions{
+		Skip: oops.TraceSkipInternal - 1,
+		Capturer: oops.CaptureFunc[stepFrames](func(err error, skip int) stepFrames {
+			return stepFrames{
+				Frames: oops.CaptureFrames(err, skip+1),
+				Step:   "load",
+			}
+		}),
+		Filters: filters,
+	})
+}
+
+func TestTraceData(t *testing.T) {
+	oops.RegisterTraceDecoder(decodeStepFrames)
+
+	err := stepTrace(dedupInner())
+
+	t.Run("Filters", func(t *testing.T) {
+		err := stepTrace(errors.New("bad stuff"), oops.MaxDepth(2))
+
+		require.Equal(t, []string{
+			"github.com/calebcase/oops_test.stepTrace",
+			"github.com/calebcase/oops_test.TestTraceData.func1",
+		}, functions(err.(*oops.TraceError).Frames()))
+	})
+
+	t.Run("Format", func(t *testing.T) {
+		output := fmt.Sprintf("%+v", err)
+		t.Log(output)
+
+		n := len(err.(*oops.TraceError).Frames()) - 1
+		require.Contains(t, output, fmt.Sprintf("··step: load\n··… %d frames in common with inner trace", n))
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		bs, jerr := json.Marshal(err)
+		require.NoError(t, jerr)
+
+		var output struct {
+			Data []map[string]any `json:"data"`
+			Step string           `json:"step"`
+		}
+		require.NoError(t, json.Unmarshal(bs, &output))
+		require.Len(t, output.Data, len(err.(*oops.TraceError).Frames()))
+		require.Equal(t, "load", output.Step)
+
+		derr, jerr := oops.UnmarshalError(bs)
+		require.NoError(t, jerr)
+		require.Equal(t, "load", derr.(*oops.TraceError).Data.(stepFrames).Step)
+	})
+}
@@ -4,3 +4,25 @@ package oops
 type unwrapper interface {
 	Unwrap() error
 }
+
+// multiUnwrapper implements the errors' package implied Unwrap interface for
+// errors wrapping multiple errors.
+type multiUnwrapper interface {
+	Unwrap() []error
+}
+
+// UnwrapAll returns the errors wrapped by err: the result of its Unwrap()
+// error or Unwrap() []error method. If err doesn't wrap any errors, then nil
+// is returned.
+func UnwrapAll(err error) []error {
+	switch u := err.(type) {
+	case unwrapper:
+		if e := u.Unwrap(); e != nil {
+			return []error{e}
+		}
+	case multiUnwrapper:
+		return u.Unwrap()
+	}
+
+	return nil
+}
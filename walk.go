@@ -0,0 +1,79 @@
+package oops
+
+// Node is an error visited by Walk.
+type Node struct {
+	Err error
+
+	// Hidden is true if the error is the hidden error of a ShadowError or
+	// is below one.
+	Hidden bool
+}
+
+// WalkAction controls how Walk continues after visiting a node.
+type WalkAction int
+
+const (
+	// WalkContinue visits the children of the node.
+	WalkContinue WalkAction = iota
+
+	// WalkSkip skips the children of the node.
+	WalkSkip
+
+	// WalkStop stops the walk.
+	WalkStop
+)
+
+// WalkFunc is called by Walk for each node. The depth of the root is 0. The
+// path is the index of each child taken from the root to reach the node (and
+// is empty for the root). The path is reused between calls and must be copied
+// to be retained.
+type WalkFunc func(node Node, depth int, path []int) WalkAction
+
+// Walk visits every error in err's tree in depth first pre-order: a node is
+// visited before its children and the children are visited in order. The
+// children of an error are the errors returned by its Unwrap method (e.g. the
+// links of a ChainError in order). The hidden error of a ShadowError is an
+// additional child after the public error.
+func Walk(err error, fn WalkFunc) {
+	if err == nil {
+		return
+	}
+
+	walk(Node{Err: err}, 0, []int{}, fn)
+}
+
+// walk visits the node and its children. It returns false if the walk was
+// stopped.
+func walk(node Node, depth int, path []int, fn WalkFunc) bool {
+	switch fn(node, depth, path) {
+	case WalkSkip:
+		return true
+	case WalkStop:
+		return false
+	}
+
+	children := UnwrapAll(node.Err)
+	hidden := make([]bool, len(children))
+
+	if se, ok := node.Err.(*ShadowError); ok && se != nil && se.Hidden != nil {
+		children = append(children, se.Hidden)
+		hidden = append(hidden, true)
+	}
+
+	for i, child := range children {
+		if child == nil {
+			continue
+		}
+
+		cnode := Node{
+			Err:    child,
+			Hidden: node.Hidden || hidden[i],
+		}
+
+		if !walk(cnode, depth+1, append(path, i), fn) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,89 @@
+package oops_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/calebcase/oops"
+	"github.com/stretchr/testify/require"
+)
+
+type walked struct {
+	Type   string
+	Hidden bool
+	Depth  int
+	Path   []int
+}
+
+func walkAll(err error, fn func(node oops.Node) oops.WalkAction) (nodes []walked) {
+	oops.Walk(err, func(node oops.Node, depth int, path []int) oops.WalkAction {
+		nodes = append(nodes, walked{
+			Type:   fmt.Sprintf("%T", node.Err),
+			Hidden: node.Hidden,
+			Depth:  depth,
+			Path:   append([]int{}, path...),
+		})
+
+		return fn(node)
+	})
+
+	return nodes
+}
+
+func TestWalk(t *testing.T) {
+	err := oops.Namespace("walk").Wrap(oops.Chain(
+		oops.Shadow(oops.Trace(io.ErrClosedPipe), io.EOF),
+		&oops.VerboseError{Err: errors.New("verbose")},
+	))
+
+	t.Run("all", func(t *testing.T) {
+		nodes := walkAll(err, func(oops.Node) oops.WalkAction {
+			return oops.WalkContinue
+		})
+
+		require.Equal(t, []walked{
+			{Type: "*oops.NamespaceError", Depth: 0, Path: []int{}},
+			{Type: "oops.ChainError", Depth: 1, Path: []int{0}},
+			{Type: "*oops.ShadowError", Depth: 2, Path: []int{0, 0}},
+			{Type: "*errors.errorString", Depth: 3, Path: []int{0, 0, 0}},
+			{Type: "*oops.TraceError", Hidden: true, Depth: 3, Path: []int{0, 0, 1}},
+			{Type: "*errors.errorString", Hidden: true, Depth: 4, Path: []int{0, 0, 1, 0}},
+			{Type: "*oops.VerboseError", Depth: 2, Path: []int{0, 1}},
+			{Type: "*errors.errorString", Depth: 3, Path: []int{0, 1, 0}},
+		}, nodes)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		nodes := walkAll(err, func(node oops.Node) oops.WalkAction {
+			if _, ok := node.Err.(*oops.ShadowError); ok {
+				return oops.WalkSkip
+			}
+
+			return oops.WalkContinue
+		})
+
+		require.Len(t, nodes, 5)
+		require.Equal(t, "*oops.VerboseError", nodes[3].Type)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		nodes := walkAll(err, func(node oops.Node) oops.WalkAction {
+			if node.Hidden {
+				return oops.WalkStop
+			}
+
+			return oops.WalkContinue
+		})
+
+		require.Len(t, nodes, 5)
+		require.True(t, nodes[4].Hidden)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.Empty(t, walkAll(nil, func(oops.Node) oops.WalkAction {
+			return oops.WalkContinue
+		}))
+	})
+}